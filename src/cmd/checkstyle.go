@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+)
+
+// Checkstyle XML format, as understood by Jenkins and GitLab plugins.
+// See https://checkstyle.sourceforge.io/ for the reference implementation.
+
+type checkstyleOutput struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// checkstyleSeverity maps linter report level to the checkstyle severity.
+func checkstyleSeverity(level int) string {
+	switch level {
+	case linter.LevelError, linter.LevelSyntax:
+		return "error"
+	case linter.LevelWarning, linter.LevelDoNotReject:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+func writeCheckstyleReports(w io.Writer, reports []*linter.Report, linterErrors []string) error {
+	perFile := make(map[string][]checkstyleError)
+	for _, r := range reports {
+		e := checkstyleError{
+			Line:     r.Line(),
+			Severity: checkstyleSeverity(r.Level()),
+			Message:  r.Message(),
+			Source:   "noverify." + r.CheckName(),
+		}
+		if r.Line() > 0 {
+			e.Column = r.StartChar() + 1
+		}
+		perFile[r.GetFilename()] = append(perFile[r.GetFilename()], e)
+	}
+
+	out := checkstyleOutput{Version: "4.3"}
+	for filename, errs := range perFile {
+		sort.SliceStable(errs, func(i, j int) bool {
+			return errs[i].Line < errs[j].Line
+		})
+		out.Files = append(out.Files, checkstyleFile{Name: filename, Errors: errs})
+	}
+	sort.Slice(out.Files, func(i, j int) bool {
+		return out.Files[i].Name < out.Files[j].Name
+	})
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	// Checkstyle has no place for errors that are not bound to
+	// a file position, so keep them as comments for a human to read.
+	for _, err := range linterErrors {
+		// "--" is not permitted inside XML comments.
+		comment := "<!-- " + strings.Replace(err, "--", "- -", -1) + " -->\n"
+		if _, err := io.WriteString(w, comment); err != nil {
+			return err
+		}
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(&out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+)
+
+// diffContextLines is a number of unchanged lines around changes in unified diff.
+const diffContextLines = 3
+
+// fixReports applies fixes attached to reports.
+//
+// In dry-run mode files are not modified and unified diff is written to w instead.
+// Returns reports that were not fixed.
+func fixReports(w io.Writer, reports []*linter.Report, dryRun bool) (unfixed []*linter.Report, err error) {
+	perFile := make(map[string][]*linter.Report)
+	for _, r := range reports {
+		perFile[r.GetFilename()] = append(perFile[r.GetFilename()], r)
+	}
+	filenames := make([]string, 0, len(perFile))
+	for filename := range perFile {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	var fixedTotal, filesTotal int
+	isFixed := make(map[*linter.Report]bool)
+	for _, filename := range filenames {
+		edits, fixed := linter.SelectFixes(perFile[filename])
+		if len(edits) == 0 {
+			continue
+		}
+
+		contents, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		if edits[len(edits)-1].EndPos > len(contents) {
+			log.Printf("Skipping fixes for %s: file was changed during analysis", filename)
+			continue
+		}
+
+		if dryRun {
+			if _, err := io.WriteString(w, unifiedDiff(filename, contents, edits)); err != nil {
+				return nil, err
+			}
+		} else if err := writeFixedFile(filename, linter.ApplyTextEdits(contents, edits)); err != nil {
+			return nil, err
+		}
+
+		for _, r := range fixed {
+			isFixed[r] = true
+		}
+		fixedTotal += len(fixed)
+		filesTotal++
+	}
+
+	if !dryRun {
+		log.Printf("Fixed %d reports in %d files", fixedTotal, filesTotal)
+	}
+
+	for _, r := range reports {
+		if !isFixed[r] {
+			unfixed = append(unfixed, r)
+		}
+	}
+	return unfixed, nil
+}
+
+// writeFixedFile replaces the file contents keeping its permissions.
+func writeFixedFile(filename string, contents []byte) error {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, contents, fi.Mode().Perm())
+}
+
+// diffChunk is a contiguous range of changed lines.
+type diffChunk struct {
+	oldFrom, oldTo int // 0-based indexes of the first and the last changed line
+	newLines       [][]byte
+}
+
+// unifiedDiff returns a unified diff between contents and contents with edits applied.
+// Edits must be sorted and non-overlapping.
+func unifiedDiff(filename string, contents []byte, edits []linter.TextEdit) string {
+	lines := bytes.SplitAfter(contents, []byte("\n"))
+	lineStarts := make([]int, len(lines))
+	pos := 0
+	for i, ln := range lines {
+		lineStarts[i] = pos
+		pos += len(ln)
+	}
+	lineIndex := func(pos int) int {
+		return sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > pos }) - 1
+	}
+
+	// Group edits by the lines they touch.
+	var chunks []diffChunk
+	var chunkEdits [][]linter.TextEdit
+	for _, e := range edits {
+		from := lineIndex(e.StartPos)
+		to := from
+		if e.EndPos > e.StartPos {
+			to = lineIndex(e.EndPos - 1)
+		}
+		if n := len(chunks); n != 0 && chunks[n-1].oldTo >= from {
+			if to > chunks[n-1].oldTo {
+				chunks[n-1].oldTo = to
+			}
+			chunkEdits[n-1] = append(chunkEdits[n-1], e)
+			continue
+		}
+		chunks = append(chunks, diffChunk{oldFrom: from, oldTo: to})
+		chunkEdits = append(chunkEdits, []linter.TextEdit{e})
+	}
+	for i := range chunks {
+		c := &chunks[i]
+		start := lineStarts[c.oldFrom]
+		end := lineStarts[c.oldTo] + len(lines[c.oldTo])
+		local := make([]linter.TextEdit, len(chunkEdits[i]))
+		for j, e := range chunkEdits[i] {
+			local[j] = linter.TextEdit{StartPos: e.StartPos - start, EndPos: e.EndPos - start, Replacement: e.Replacement}
+		}
+		c.newLines = bytes.SplitAfter(linter.ApplyTextEdits(contents[start:end], local), []byte("\n"))
+		if len(c.newLines[len(c.newLines)-1]) == 0 {
+			c.newLines = c.newLines[:len(c.newLines)-1]
+		}
+	}
+
+	var out bytes.Buffer
+	filename = relativeFilename(filename)
+	if filepath.IsAbs(filename) {
+		fmt.Fprintf(&out, "--- %s\n+++ %s\n", filename, filename)
+	} else {
+		fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", filename, filename)
+	}
+
+	delta := 0 // new line index minus old line index
+	for i := 0; i < len(chunks); {
+		// Chunks that are close enough are printed inside a single hunk.
+		j := i + 1
+		for j < len(chunks) && chunks[j].oldFrom-chunks[j-1].oldTo-1 <= 2*diffContextLines {
+			j++
+		}
+
+		from := chunks[i].oldFrom - diffContextLines
+		if from < 0 {
+			from = 0
+		}
+		to := chunks[j-1].oldTo + diffContextLines
+		if to >= len(lines) {
+			to = len(lines) - 1
+		}
+		if len(lines[to]) == 0 && to > chunks[j-1].oldTo {
+			to-- // Empty "line" after the trailing newline.
+		}
+
+		var hunk bytes.Buffer
+		oldCount, newCount := 0, 0
+		line := from
+		for _, c := range chunks[i:j] {
+			for ; line < c.oldFrom; line++ {
+				writeDiffLine(&hunk, ' ', lines[line])
+				oldCount++
+				newCount++
+			}
+			for ; line <= c.oldTo; line++ {
+				writeDiffLine(&hunk, '-', lines[line])
+				oldCount++
+			}
+			for _, ln := range c.newLines {
+				writeDiffLine(&hunk, '+', ln)
+				newCount++
+			}
+		}
+		for ; line <= to; line++ {
+			writeDiffLine(&hunk, ' ', lines[line])
+			oldCount++
+			newCount++
+		}
+
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", from+1, oldCount, from+delta+1, newCount)
+		out.Write(hunk.Bytes())
+
+		delta += newCount - oldCount
+		i = j
+	}
+
+	return out.String()
+}
+
+func writeDiffLine(w *bytes.Buffer, prefix byte, ln []byte) {
+	w.WriteByte(prefix)
+	w.Write(ln)
+	if !bytes.HasSuffix(ln, []byte("\n")) {
+		w.WriteString("\n\\ No newline at end of file\n")
+	}
+}
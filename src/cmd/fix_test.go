@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestFixKeepsFileMode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "noverify-fix")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "fix.php")
+	before := `<?php function f($x = array(1)) { return $x; }`
+	if err := ioutil.WriteFile(filename, []byte(before), 0600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	// Make sure the mode doesn't depend on umask.
+	if err := os.Chmod(filename, 0751); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+
+	test := linttest.NewSuite(t)
+	test.Files = append(test.Files, linttest.TestFile{Name: filename, Data: []byte(before)})
+	if _, err := fixReports(ioutil.Discard, test.RunLinter(), false); err != nil {
+		t.Fatalf("fix reports: %v", err)
+	}
+
+	after, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	if want := `<?php function f($x = [1]) { return $x; }`; string(after) != want {
+		t.Errorf("fixed contents mismatch:\nhave: %s\nwant: %s", after, want)
+	}
+	fi, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if mode := fi.Mode().Perm(); mode != 0751 {
+		t.Errorf("file mode changed: have %o, want %o", mode, 0751)
+	}
+}
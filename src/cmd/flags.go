@@ -19,6 +19,8 @@ var (
 
 	gitRepo string
 
+	configFile string
+
 	pprofHost string
 
 	gitCommitFrom       string
@@ -39,18 +41,34 @@ var (
 	reportsExcludeChecksSet map[string]bool
 	reportsIncludeChecksSet map[string]bool
 	reportsCriticalSet      map[string]bool
+	reportsSeverity         string
 
 	allowChecks       string
 	allowDisable      string
 	allowDisableRegex *regexp.Regexp
 
+	unusedSymbolsAllow string
+
+	taintSources    string
+	taintSinks      string
+	taintSanitizers string
+
 	unusedVarPattern string
 
 	fullAnalysisFiles string
 	indexOnlyFiles    string
 
-	output     string
-	outputJSON bool
+	baselineFile      string
+	baselineWriteFile string
+	reportsBaseline   *linter.Baseline
+
+	fix       bool
+	fixDryRun bool
+
+	output       string
+	outputJSON   bool
+	outputFormat string
+	junitGroupBy string
 
 	version bool
 
@@ -86,11 +104,16 @@ func bindFlags() {
 		}
 	}
 
+	flag.StringVar(&configFile, "config", "", "Project config `file` (noverify.json or .noverify.yml), by default it's searched in the project root and its parents")
+
 	flag.StringVar(&pprofHost, "pprof", "", "HTTP pprof endpoint (e.g. localhost:8080)")
 
 	flag.StringVar(&reportsCritical, "critical", allNonMaybe,
 		"Comma-separated list of check names that are considered critical (all non-maybe checks by default)")
 
+	flag.StringVar(&reportsSeverity, "severity", "",
+		"Comma-separated list of check:level pairs that override check report levels (e.g. phpdoc:error,deadCode:hint); levels are error, warning, info, hint, unused and maybe")
+
 	flag.StringVar(&gitRepo, "git", "", "Path to git repository to analyze")
 	flag.StringVar(&gitCommitFrom, "git-commit-from", "", "Analyze changes between commits <git-commit-from> and <git-commit-to>")
 	flag.StringVar(&gitCommitTo, "git-commit-to", "", "")
@@ -105,6 +128,10 @@ func bindFlags() {
 	flag.StringVar(&reportsExclude, "exclude", "", "Exclude regexp for filenames in reports list")
 	flag.StringVar(&reportsExcludeChecks, "exclude-checks", "", "Comma-separated list of check names to be excluded")
 	flag.StringVar(&allowDisable, "allow-disable", "", "Regexp for filenames where '@linter disable' is allowed")
+	flag.StringVar(&unusedSymbolsAllow, "unused-symbols-allow", "", "Regexp for names of symbols that are used implicitly (controllers, hooks, etc) and are not reported by unusedSymbol check")
+	flag.StringVar(&taintSources, "taint-sources", "", "Comma-separated list of functions and class::method names that return user input, used by taint check")
+	flag.StringVar(&taintSinks, "taint-sinks", "", "Comma-separated list of functions and class::method names that must not get user input, used by taint check")
+	flag.StringVar(&taintSanitizers, "taint-sanitizers", "", "Comma-separated list of functions and class::method names that make user input safe, used by taint check")
 	flag.StringVar(&allowChecks, "allow-checks", strings.Join(enabledByDefault, ","),
 		"Comma-separated list of check names to be enabled")
 
@@ -113,8 +140,16 @@ func bindFlags() {
 	flag.StringVar(&fullAnalysisFiles, "full-analysis-files", "", "Comma-separated list of files to do full analysis")
 	flag.StringVar(&indexOnlyFiles, "index-only-files", "", "Comma-separated list of files to do indexing")
 
+	flag.StringVar(&baselineFile, "baseline", "", "Do not report issues that are listed in the specified baseline `file`")
+	flag.StringVar(&baselineWriteFile, "baseline-write", "", "Write all found reports to the specified baseline `file` instead of reporting them")
+
+	flag.BoolVar(&fix, "fix", false, "Apply automatic fixes to the analyzed files and report only issues that were not fixed")
+	flag.BoolVar(&fixDryRun, "fix-dry-run", false, "Output automatic fixes as a unified diff instead of reports, files are not modified")
+
 	flag.StringVar(&output, "output", "", "Output reports to a specified file instead of stderr")
-	flag.BoolVar(&outputJSON, "output-json", false, "Format output as JSON")
+	flag.BoolVar(&outputJSON, "output-json", false, "Format output as JSON (same as -output-format=json)")
+	flag.StringVar(&outputFormat, "output-format", "text", "Reports output format: text, json, sarif, checkstyle, junit, github, gitlab or html")
+	flag.StringVar(&junitGroupBy, "junit-group-by", "check", "Make a JUnit testcase per check or per file (for -output-format=junit)")
 
 	flag.BoolVar(&linter.CheckAutoGenerated, `check-auto-generated`, false, "whether to lint auto-generated PHP file")
 	flag.BoolVar(&linter.Debug, "debug", false, "Enable debug output")
@@ -62,6 +62,7 @@ func gitRepoComputeReportsFromCommits(logArgs, diffArgs []string) (oldReports, r
 		log.Printf("Indexed old commit in %s", time.Since(start))
 
 		meta.SetIndexingComplete(true)
+		linter.UpdateTaintSummaries(linter.ReadFilesFromGit(gitRepo, gitCommitFrom, nil))
 
 		start = time.Now()
 		oldReports = linter.ParseFilenames(linter.ReadFilesFromGit(gitRepo, gitCommitFrom, linter.ExcludeRegex))
@@ -75,6 +76,7 @@ func gitRepoComputeReportsFromCommits(logArgs, diffArgs []string) (oldReports, r
 		log.Printf("Indexed new commit in %s", time.Since(start))
 
 		meta.SetIndexingComplete(true)
+		linter.UpdateTaintSummaries(linter.ReadFilesFromGit(gitRepo, gitCommitTo, nil))
 
 		start = time.Now()
 		reports = linter.ParseFilenames(linter.ReadFilesFromGit(gitRepo, gitCommitTo, linter.ExcludeRegex))
@@ -86,6 +88,7 @@ func gitRepoComputeReportsFromCommits(logArgs, diffArgs []string) (oldReports, r
 		log.Printf("Indexing complete in %s", time.Since(start))
 
 		meta.SetIndexingComplete(true)
+		linter.UpdateTaintSummaries(linter.ReadFilesFromGit(gitRepo, gitCommitTo, nil))
 
 		start = time.Now()
 		oldReports = linter.ParseFilenames(linter.ReadOldFilesFromGit(gitRepo, gitCommitFrom, changes))
@@ -95,6 +98,7 @@ func gitRepoComputeReportsFromCommits(logArgs, diffArgs []string) (oldReports, r
 		meta.SetIndexingComplete(false)
 		linter.ParseFilenames(linter.ReadFilesFromGitWithChanges(gitRepo, gitCommitTo, changes))
 		meta.SetIndexingComplete(true)
+		linter.UpdateTaintSummaries(linter.ReadFilesFromGitWithChanges(gitRepo, gitCommitTo, changes))
 		log.Printf("Indexed files versions for %s", time.Since(start))
 
 		start = time.Now()
@@ -130,6 +134,7 @@ func gitRepoComputeReportsFromLocalChanges() (oldReports, reports []*linter.Repo
 	log.Printf("Indexing complete in %s", time.Since(start))
 
 	meta.SetIndexingComplete(true)
+	linter.UpdateTaintSummaries(linter.ReadFilesFromGit(gitRepo, gitCommitFrom, nil))
 
 	start = time.Now()
 	oldReports = linter.ParseFilenames(linter.ReadOldFilesFromGit(gitRepo, gitCommitFrom, changes))
@@ -140,6 +145,7 @@ func gitRepoComputeReportsFromLocalChanges() (oldReports, reports []*linter.Repo
 	linter.ParseFilenames(linter.ReadChangesFromWorkTree(gitWorkTree, changes))
 	gitParseUntracked()
 	meta.SetIndexingComplete(true)
+	linter.UpdateTaintSummaries(linter.ReadChangesFromWorkTree(gitWorkTree, changes))
 	log.Printf("Indexed new files versions for %s", time.Since(start))
 
 	start = time.Now()
@@ -180,7 +186,10 @@ func gitMain() (int, error) {
 	}
 	log.Printf("Computed reports diff for %s", time.Since(start))
 
-	criticalReports := analyzeReports(diff)
+	criticalReports, err := analyzeReports(diff)
+	if err != nil {
+		return 0, err
+	}
 
 	if criticalReports > 0 {
 		log.Printf("Found %d critical issues, please fix them.", criticalReports)
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+)
+
+// GitHub Actions workflow commands format.
+// See https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions.
+
+// githubCommand maps linter report level to the workflow command name.
+func githubCommand(level int) string {
+	switch level {
+	case linter.LevelError, linter.LevelSyntax:
+		return "error"
+	case linter.LevelWarning, linter.LevelDoNotReject:
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+var (
+	githubDataEscaper = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+	)
+	githubPropertyEscaper = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+		":", "%3A",
+		",", "%2C",
+	)
+)
+
+func writeGitHubReports(w io.Writer, reports []*linter.Report, linterErrors []string) error {
+	for _, err := range linterErrors {
+		if _, err := fmt.Fprintf(w, "::error::%s\n", githubDataEscaper.Replace(err)); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range reports {
+		props := []string{
+			"file=" + githubPropertyEscaper.Replace(relativeFilename(r.GetFilename())),
+			fmt.Sprintf("line=%d", r.Line()),
+		}
+		if r.Line() > 0 {
+			// GitHub columns are 1-based, end column is inclusive.
+			props = append(props, fmt.Sprintf("col=%d", r.StartChar()+1))
+			if r.EndLine() > r.Line() {
+				props = append(props, fmt.Sprintf("endLine=%d", r.EndLine()))
+			} else if r.EndChar() > r.StartChar() {
+				props = append(props, fmt.Sprintf("endColumn=%d", r.EndChar()))
+			}
+		}
+		props = append(props, "title="+githubPropertyEscaper.Replace("noverify: "+r.CheckName()))
+
+		_, err := fmt.Fprintf(w, "::%s %s::%s\n",
+			githubCommand(r.Level()), strings.Join(props, ","), githubDataEscaper.Replace(r.Message()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+)
+
+// GitLab Code Quality report format.
+// See https://docs.gitlab.com/ee/user/project/merge_requests/code_quality.html.
+
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitlabLines `json:"lines"`
+}
+
+type gitlabLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end,omitempty"`
+}
+
+// gitlabSeverity maps linter report level to the Code Quality severity.
+func gitlabSeverity(level int) string {
+	switch level {
+	case linter.LevelSyntax:
+		return "blocker"
+	case linter.LevelError:
+		return "critical"
+	case linter.LevelWarning:
+		return "major"
+	case linter.LevelDoNotReject:
+		return "minor"
+	default:
+		return "info"
+	}
+}
+
+func writeGitLabReports(w io.Writer, reports []*linter.Report, linterErrors []string) error {
+	// Code Quality report has no place for errors
+	// without a location, so they go to the job log.
+	for _, err := range linterErrors {
+		log.Print(err)
+	}
+
+	issues := make([]gitlabIssue, 0, len(reports))
+	for _, r := range reports {
+		path := relativeFilename(r.GetFilename())
+
+		issues = append(issues, gitlabIssue{
+			Description: r.Message(),
+			CheckName:   r.CheckName(),
+			Fingerprint: r.Fingerprint(),
+			Severity:    gitlabSeverity(r.Level()),
+			Location: gitlabLocation{
+				Path:  path,
+				Lines: gitlabLines{Begin: r.Line(), End: r.EndLine()},
+			},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
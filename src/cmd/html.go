@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+)
+
+// Self-contained HTML report: no external styles, scripts or images,
+// so it can be opened right from the CI artifacts page.
+
+const (
+	// htmlSnippetContext is a number of lines shown before and after the reported lines.
+	htmlSnippetContext = 2
+
+	// htmlSnippetMaxLines limits the number of reported lines shown in a snippet.
+	htmlSnippetMaxLines = 10
+)
+
+type htmlReportData struct {
+	Total    int
+	Critical int
+	Errors   []string
+	Checks   []htmlSummaryRow
+	Dirs     []htmlSummaryRow
+	Reports  []htmlReportRow
+}
+
+type htmlSummaryRow struct {
+	Name     string
+	Total    int
+	Critical int
+}
+
+type htmlReportRow struct {
+	Severity string
+	Critical bool
+	Check    string
+	Filename string
+	Line     int
+	Message  string
+	Snippet  []htmlSnippetLine
+}
+
+type htmlSnippetLine struct {
+	Num      int
+	Reported bool
+
+	// Line text is split into 3 parts, so the reported range can be highlighted.
+	Before string
+	Marked string
+	After  string
+}
+
+func writeHTMLReports(w io.Writer, reports []*linter.Report, linterErrors []string) error {
+	data := htmlReportData{
+		Total:  len(reports),
+		Errors: linterErrors,
+	}
+
+	checks := make(map[string]*htmlSummaryRow)
+	dirs := make(map[string]*htmlSummaryRow)
+
+	for _, r := range reports {
+		filename := relativeFilename(r.GetFilename())
+		critical := isCritical(r)
+		if critical {
+			data.Critical++
+		}
+		addHTMLSummary(checks, r.CheckName(), critical)
+		addHTMLSummary(dirs, filepath.ToSlash(filepath.Dir(filename)), critical)
+
+		data.Reports = append(data.Reports, htmlReportRow{
+			Severity: linter.LevelName(r.Level()),
+			Critical: critical,
+			Check:    r.CheckName(),
+			Filename: filename,
+			Line:     r.Line(),
+			Message:  r.Message(),
+			Snippet:  htmlSnippet(r),
+		})
+	}
+
+	data.Checks = sortedHTMLSummary(checks)
+	data.Dirs = sortedHTMLSummary(dirs)
+	sort.SliceStable(data.Reports, func(i, j int) bool {
+		x, y := data.Reports[i], data.Reports[j]
+		if x.Filename != y.Filename {
+			return x.Filename < y.Filename
+		}
+		return x.Line < y.Line
+	})
+
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, &data); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func addHTMLSummary(m map[string]*htmlSummaryRow, name string, critical bool) {
+	row, ok := m[name]
+	if !ok {
+		row = &htmlSummaryRow{Name: name}
+		m[name] = row
+	}
+	row.Total++
+	if critical {
+		row.Critical++
+	}
+}
+
+// sortedHTMLSummary returns summary rows, the most reported ones go first.
+func sortedHTMLSummary(m map[string]*htmlSummaryRow) []htmlSummaryRow {
+	rows := make([]htmlSummaryRow, 0, len(m))
+	for _, row := range m {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Total != rows[j].Total {
+			return rows[i].Total > rows[j].Total
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows
+}
+
+// htmlSnippet returns source code lines around the report.
+//
+// If the snippet was not saved by the linter,
+// only the reported line is shown.
+func htmlSnippet(r *linter.Report) []htmlSnippetLine {
+	start, end := r.Line(), r.EndLine()
+	if start <= 0 {
+		return nil
+	}
+	first, lines := r.Snippet()
+	if len(lines) == 0 {
+		first, lines = start, []string{r.Context()}
+		end = start
+	}
+	if end < start {
+		end = start
+	}
+	if end > start+htmlSnippetMaxLines-1 {
+		end = start + htmlSnippetMaxLines - 1
+	}
+
+	var snippet []htmlSnippetLine
+	for i, text := range lines {
+		num := first + i
+		ln := htmlSnippetLine{Num: num, Before: text}
+		if num >= start && num <= end {
+			ln.Reported = true
+			if start == r.EndLine() {
+				ln.Before, ln.Marked, ln.After = splitMarked(text, r.StartChar(), r.EndChar())
+			}
+		}
+		snippet = append(snippet, ln)
+	}
+	return snippet
+}
+
+// splitMarked splits s into parts before, inside and after [from, to) range.
+func splitMarked(s string, from, to int) (before, marked, after string) {
+	if from < 0 || to > len(s) || from >= to {
+		return s, "", ""
+	}
+	return s[:from], s[from:to], s[to:]
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>NoVerify report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.2em; margin-top: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+table.sortable th { cursor: pointer; user-select: none; }
+table.sortable th:after { content: " \2195"; color: #999; }
+.summary { display: inline-block; margin-right: 2em; vertical-align: top; }
+.critical { color: #b00; font-weight: bold; }
+.errors li { color: #b00; }
+pre { margin: 4px 0; background: #fafafa; border: 1px solid #eee; padding: 4px; overflow-x: auto; }
+.ln { color: #999; display: inline-block; min-width: 3em; text-align: right; margin-right: 1em; user-select: none; }
+.reported { background: #fff3c4; }
+mark { background: #ffb347; }
+details summary { cursor: pointer; }
+</style>
+</head>
+<body>
+<h1>NoVerify report</h1>
+<p>Found {{.Total}} reports, <span class="critical">{{.Critical}} critical</span>.</p>
+
+{{if .Errors}}
+<h2>Errors</h2>
+<ul class="errors">
+{{range .Errors}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}
+
+<div class="summary">
+<h2>By check</h2>
+<table class="sortable">
+<thead><tr><th>Check</th><th>Reports</th><th>Critical</th></tr></thead>
+<tbody>
+{{range .Checks}}<tr><td>{{.Name}}</td><td>{{.Total}}</td><td>{{.Critical}}</td></tr>
+{{end}}</tbody>
+</table>
+</div>
+
+<div class="summary">
+<h2>By directory</h2>
+<table class="sortable">
+<thead><tr><th>Directory</th><th>Reports</th><th>Critical</th></tr></thead>
+<tbody>
+{{range .Dirs}}<tr><td>{{.Name}}</td><td>{{.Total}}</td><td>{{.Critical}}</td></tr>
+{{end}}</tbody>
+</table>
+</div>
+
+<h2>Reports</h2>
+<table class="sortable">
+<thead><tr><th>Severity</th><th>Check</th><th>File</th><th>Line</th><th>Message</th></tr></thead>
+<tbody>
+{{range .Reports}}<tr>
+<td{{if .Critical}} class="critical"{{end}}>{{.Severity}}</td>
+<td>{{.Check}}</td>
+<td>{{.Filename}}</td>
+<td>{{.Line}}</td>
+<td>{{if .Snippet}}<details><summary>{{.Message}}</summary>
+<pre>{{range .Snippet}}<div{{if .Reported}} class="reported"{{end}}><span class="ln">{{.Num}}</span>{{.Before}}{{if .Marked}}<mark>{{.Marked}}</mark>{{.After}}{{end}}</div>{{end}}</pre>
+</details>{{else}}{{.Message}}{{end}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+
+<script>
+document.querySelectorAll("table.sortable").forEach(function(table) {
+  table.querySelectorAll("th").forEach(function(th, col) {
+    var asc = true;
+    th.addEventListener("click", function() {
+      var tbody = table.tBodies[0];
+      var rows = Array.prototype.slice.call(tbody.rows);
+      rows.sort(function(a, b) {
+        var x = a.cells[col].innerText, y = b.cells[col].innerText;
+        var nx = parseFloat(x), ny = parseFloat(y);
+        var res = (!isNaN(nx) && !isNaN(ny)) ? nx - ny : x.localeCompare(y);
+        return asc ? res : -res;
+      });
+      asc = !asc;
+      rows.forEach(function(row) { tbody.appendChild(row); });
+    });
+  });
+});
+</script>
+</body>
+</html>
+`))
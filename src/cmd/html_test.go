@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestHTMLReportSnippet(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+function f() {
+  $before = 1;
+  echo $undefined;
+  $after = 2;
+  return $before + $after;
+}
+`)
+
+	linter.SnippetContextLines = htmlSnippetContext
+	linter.SnippetMaxLines = htmlSnippetMaxLines
+	defer func() {
+		linter.SnippetContextLines = 0
+		linter.SnippetMaxLines = 0
+	}()
+
+	// Analyzed file doesn't exist on disk, so the snippet
+	// can only come from the source the linter has read.
+	var buf bytes.Buffer
+	if err := writeHTMLReports(&buf, test.RunLinter(), nil); err != nil {
+		t.Fatalf("write report: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"$before = 1;", "<mark>$undefined</mark>", "$after = 2;"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("snippet line %q not found in the report", want)
+		}
+	}
+}
+
+func TestHTMLReportNoSnippet(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+function f() {
+  $before = 1;
+  echo $undefined;
+  return $before;
+}
+`)
+
+	// Snippets are not saved by default, only the reported line is shown.
+	reports := test.RunLinter()
+	for _, r := range reports {
+		if _, lines := r.Snippet(); len(lines) != 0 {
+			t.Errorf("unexpected snippet for %s", r)
+		}
+	}
+	var buf bytes.Buffer
+	if err := writeHTMLReports(&buf, reports, nil); err != nil {
+		t.Fatalf("write report: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "<mark>$undefined</mark>") {
+		t.Errorf("reported line not found in the report")
+	}
+	if strings.Contains(out, "$before = 1;") {
+		t.Errorf("unexpected snippet line in the report")
+	}
+}
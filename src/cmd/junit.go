@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+)
+
+// JUnit XML format, as understood by Jenkins and GitLab test reports.
+//
+// Every check (or every file, see -junit-group-by) becomes a testcase.
+// Testcase fails if it has at least one critical report.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitGroupKey returns testcase name for the report according to -junit-group-by.
+func junitGroupKey(r *linter.Report) string {
+	if junitGroupBy == "file" {
+		return r.GetFilename()
+	}
+	return r.CheckName()
+}
+
+func writeJUnitReports(w io.Writer, reports []*linter.Report, linterErrors []string) error {
+	groups := make(map[string][]*linter.Report)
+	if junitGroupBy != "file" {
+		// Report passed checks as well, so they're visible as succeeded tests.
+		for _, info := range linter.GetDeclaredChecks() {
+			if reportsIncludeChecksSet[info.Name] && !reportsExcludeChecksSet[info.Name] {
+				groups[info.Name] = nil
+			}
+		}
+	}
+	for _, r := range reports {
+		key := junitGroupKey(r)
+		groups[key] = append(groups[key], r)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	suite := junitTestSuite{Name: "noverify"}
+	for _, name := range names {
+		tc := junitTestCase{Name: name, ClassName: "noverify." + junitGroupBy}
+
+		var critical, other strings.Builder
+		var criticalCount int
+		for _, r := range groups[name] {
+			if isCritical(r) {
+				criticalCount++
+				critical.WriteString(r.String())
+				critical.WriteString("\n")
+			} else {
+				other.WriteString(r.String())
+				other.WriteString("\n")
+			}
+		}
+		if criticalCount != 0 {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("found %d critical report(s)", criticalCount),
+				Type:    "critical",
+				Text:    critical.String(),
+			}
+			suite.Failures++
+		}
+		tc.SystemOut = other.String()
+
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	if len(linterErrors) != 0 {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      "linter disable",
+			ClassName: "noverify",
+			Failure: &junitFailure{
+				Message: "'@linter disable' is not allowed",
+				Type:    "error",
+				Text:    strings.Join(linterErrors, "\n"),
+			},
+		})
+		suite.Failures++
+	}
+	suite.Tests = len(suite.TestCases)
+
+	out := junitTestSuites{
+		Name:     "noverify",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(&out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -27,18 +26,33 @@ import (
 //go:generate go-bindata -pkg stubs -nometadata -o ./stubs/phpstorm_stubs.go -ignore=\.idea -ignore=\.git ./stubs/phpstorm-stubs/...
 
 func isCritical(r *linter.Report) bool {
-	if len(reportsCriticalSet) != 0 {
-		return reportsCriticalSet[r.CheckName()]
+	criticalSet := reportsCriticalSet
+	if o := findPathOverride(r.GetFilename()); o != nil && o.criticalOverridden {
+		criticalSet = o.criticalSet
+	}
+	if len(criticalSet) != 0 {
+		return criticalSet[r.CheckName()]
 	}
 	return r.IsCritical()
 }
 
 func isEnabled(r *linter.Report) bool {
-	if !reportsIncludeChecksSet[r.CheckName()] {
+	includeChecksSet := reportsIncludeChecksSet
+	excludeChecksSet := reportsExcludeChecksSet
+	if o := findPathOverride(r.GetFilename()); o != nil {
+		if o.includeChecksSet != nil {
+			includeChecksSet = o.includeChecksSet
+		}
+		if o.excludeChecksSet != nil {
+			excludeChecksSet = o.excludeChecksSet
+		}
+	}
+
+	if !includeChecksSet[r.CheckName()] {
 		return false // Not enabled by -allow-checks
 	}
 
-	if reportsExcludeChecksSet[r.CheckName()] {
+	if excludeChecksSet[r.CheckName()] {
 		return false // Disabled by -exclude-checks
 	}
 
@@ -92,6 +106,11 @@ func mainNoExit() (int, error) {
 		return 0, nil
 	}
 
+	// Profiling flags can be set in the config, so it's loaded first.
+	if err := loadProjectConfig(); err != nil {
+		return 0, fmt.Errorf("Could not load project config: %v", err)
+	}
+
 	if pprofHost != "" {
 		go http.ListenAndServe(pprofHost, nil)
 	}
@@ -134,6 +153,42 @@ func mainNoExit() (int, error) {
 	}
 
 	buildCheckMappings()
+	if err := parseSeverityOverrides(); err != nil {
+		return 0, err
+	}
+
+	// Function taint summaries are computed during indexing,
+	// so taint analysis is enabled before it.
+	linter.TaintAnalysis = reportsIncludeChecksSet["taint"] && !reportsExcludeChecksSet["taint"]
+	addTaintFunctions(linter.TaintSources, taintSources)
+	addTaintFunctions(linter.TaintSinks, taintSinks)
+	addTaintFunctions(linter.TaintSanitizers, taintSanitizers)
+
+	if outputJSON {
+		outputFormat = "json"
+	}
+	if _, ok := reportsWriters[outputFormat]; !ok {
+		return 0, fmt.Errorf("Unknown output format %q", outputFormat)
+	}
+	if outputFormat == "html" {
+		linter.SnippetContextLines = htmlSnippetContext
+		linter.SnippetMaxLines = htmlSnippetMaxLines
+	}
+	if junitGroupBy != "check" && junitGroupBy != "file" {
+		return 0, fmt.Errorf("Unknown JUnit grouping %q, expected check or file", junitGroupBy)
+	}
+
+	if (fix || fixDryRun) && gitRepo != "" {
+		return 0, fmt.Errorf("-fix and -fix-dry-run can't be used in git mode")
+	}
+
+	if baselineFile != "" {
+		var err error
+		reportsBaseline, err = readBaseline(baselineFile)
+		if err != nil {
+			return 0, fmt.Errorf("Could not read baseline: %v", err)
+		}
+	}
 
 	lintdebug.Register(func(msg string) { linter.DebugMessage("%s", msg) })
 	go linter.MemoryLimiterThread()
@@ -167,6 +222,7 @@ func mainNoExit() (int, error) {
 	log.Printf("Indexing %+v", flag.Args())
 	linter.ParseFilenames(linter.ReadFilenames(flag.Args(), nil))
 	meta.SetIndexingComplete(true)
+	linter.UpdateTaintSummaries(linter.ReadFilenames(flag.Args(), nil))
 	log.Printf("Linting")
 
 	filenames := flag.Args()
@@ -174,8 +230,19 @@ func mainNoExit() (int, error) {
 		filenames = strings.Split(fullAnalysisFiles, ",")
 	}
 
+	// Unused symbols can only be found when all files are analyzed,
+	// so this check is not available in git mode and for partial analysis.
+	linter.UnusedSymbols = fullAnalysisFiles == "" &&
+		reportsIncludeChecksSet["unusedSymbol"] && !reportsExcludeChecksSet["unusedSymbol"]
+
 	reports := linter.ParseFilenames(linter.ReadFilenames(filenames, linter.ExcludeRegex))
-	criticalReports := analyzeReports(reports)
+	if linter.UnusedSymbols {
+		reports = append(reports, linter.UnusedSymbolReports()...)
+	}
+	criticalReports, err := analyzeReports(reports)
+	if err != nil {
+		return 0, err
+	}
 
 	if criticalReports > 0 {
 		log.Printf("Found %d critical reports", criticalReports)
@@ -201,18 +268,35 @@ func compileRegexes() error {
 		}
 	}
 
+	if unusedSymbolsAllow != "" {
+		linter.UnusedSymbolsAllowRegex, err = regexp.Compile(unusedSymbolsAllow)
+		if err != nil {
+			return fmt.Errorf("Incorrect 'unused symbols allow' regex: %v", err)
+		}
+	}
+
 	return nil
 }
 
-func buildCheckMappings() {
-	stringToSet := func(s string) map[string]bool {
-		set := make(map[string]bool)
-		for _, name := range strings.Split(s, ",") {
-			set[strings.TrimSpace(name)] = true
+func stringToSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, name := range strings.Split(s, ",") {
+		set[strings.TrimSpace(name)] = true
+	}
+	return set
+}
+
+// addTaintFunctions adds comma-separated function names from list to the set.
+func addTaintFunctions(set map[string]struct{}, list string) {
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), `\`))
+		if name != "" {
+			set[name] = struct{}{}
 		}
-		return set
 	}
+}
 
+func buildCheckMappings() {
 	reportsExcludeChecksSet = stringToSet(reportsExcludeChecks)
 	reportsIncludeChecksSet = stringToSet(allowChecks)
 	if reportsCritical != allNonMaybe {
@@ -220,7 +304,41 @@ func buildCheckMappings() {
 	}
 }
 
-func analyzeReports(diff []*linter.Report) (criticalReports int) {
+// parseSeverityOverrides fills linter.SeverityOverrides from the -severity argument.
+func parseSeverityOverrides() error {
+	if reportsSeverity == "" {
+		return nil
+	}
+
+	declared := make(map[string]bool)
+	for _, info := range linter.GetDeclaredChecks() {
+		declared[info.Name] = true
+	}
+
+	linter.SeverityOverrides = make(map[string]int)
+	for _, pair := range strings.Split(reportsSeverity, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		colon := strings.IndexByte(pair, ':')
+		if colon == -1 {
+			return fmt.Errorf("Incorrect severity override %q, expected check:level", pair)
+		}
+		checkName, levelName := pair[:colon], pair[colon+1:]
+		if !declared[checkName] {
+			return fmt.Errorf("Unknown check %q in severity overrides", checkName)
+		}
+		level, ok := linter.ParseLevel(levelName)
+		if !ok {
+			return fmt.Errorf("Unknown level %q for %s severity override", levelName, checkName)
+		}
+		linter.SeverityOverrides[checkName] = level
+	}
+	return nil
+}
+
+func analyzeReports(diff []*linter.Report) (criticalReports int, err error) {
 	filtered := make([]*linter.Report, 0, len(diff))
 	var linterErrors []string
 	for _, r := range diff {
@@ -238,40 +356,67 @@ func analyzeReports(diff []*linter.Report) (criticalReports int) {
 		}
 
 		filtered = append(filtered, r)
+	}
 
-		if isCritical(r) {
-			criticalReports++
+	if baselineWriteFile != "" {
+		if err := writeBaseline(baselineWriteFile, filtered); err != nil {
+			return 0, fmt.Errorf("Could not write baseline: %v", err)
 		}
+		log.Printf("Written %d reports to the baseline %s", len(filtered), baselineWriteFile)
+		return 0, nil
 	}
 
-	if outputJSON {
-		type reportList struct {
-			Reports []*linter.Report
-			Errors  []string
-		}
-		list := &reportList{
-			Reports: filtered,
-			Errors:  linterErrors,
+	if reportsBaseline != nil {
+		var stale []linter.BaselineEntry
+		filtered, stale = reportsBaseline.Filter(filtered)
+		for _, e := range stale {
+			log.Printf("Stale baseline entry (can be removed): %s", e.String())
 		}
-		d := json.NewEncoder(outputFp)
-		if err := d.Encode(list); err != nil {
-			// Should never fail to marshal our own reports.
-			panic(fmt.Sprintf("report list marshaling failed: %v", err))
+	}
+
+	if fix || fixDryRun {
+		var err error
+		filtered, err = fixReports(outputFp, filtered, fixDryRun)
+		if err != nil {
+			return 0, fmt.Errorf("Could not apply fixes: %v", err)
 		}
-	} else {
-		for _, err := range linterErrors {
-			fmt.Fprintf(outputFp, "%s\n", err)
+		if fixDryRun {
+			return 0, nil
 		}
-		for _, r := range filtered {
-			if isCritical(r) {
-				fmt.Fprintf(outputFp, "<critical> %s\n", r.String())
-			} else {
-				fmt.Fprintf(outputFp, "%s\n", r.String())
-			}
+	}
+
+	for _, r := range filtered {
+		if isCritical(r) {
+			criticalReports++
 		}
 	}
 
-	return criticalReports
+	if err := reportsWriters[outputFormat](outputFp, filtered, linterErrors); err != nil {
+		return 0, fmt.Errorf("write %s reports: %v", outputFormat, err)
+	}
+
+	return criticalReports, nil
+}
+
+func readBaseline(filename string) (*linter.Baseline, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return linter.ReadBaseline(f)
+}
+
+func writeBaseline(filename string, reports []*linter.Report) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := linter.NewBaseline(reports).Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
 func setDiscardVarPredicate() error {
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+)
+
+// reportsWriter outputs filtered reports along with linter errors
+// (like forbidden '@linter disable' usages) in some specific format.
+type reportsWriter func(w io.Writer, reports []*linter.Report, linterErrors []string) error
+
+// reportsWriters maps -output-format values to their implementations.
+var reportsWriters = map[string]reportsWriter{
+	"text":       writeTextReports,
+	"json":       writeJSONReports,
+	"sarif":      writeSarifReports,
+	"checkstyle": writeCheckstyleReports,
+	"junit":      writeJUnitReports,
+	"github":     writeGitHubReports,
+	"gitlab":     writeGitLabReports,
+	"html":       writeHTMLReports,
+}
+
+// relativeFilename makes filename relative to the working directory (if possible)
+// and converts it to the slash-separated form, as expected by CI systems.
+func relativeFilename(filename string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, filename); err == nil && !strings.HasPrefix(rel, "..") {
+			filename = rel
+		}
+	}
+	return filepath.ToSlash(filename)
+}
+
+func writeTextReports(w io.Writer, reports []*linter.Report, linterErrors []string) error {
+	for _, err := range linterErrors {
+		fmt.Fprintf(w, "%s\n", err)
+	}
+	for _, r := range reports {
+		if isCritical(r) {
+			fmt.Fprintf(w, "<critical> %s\n", r.String())
+		} else {
+			fmt.Fprintf(w, "%s\n", r.String())
+		}
+	}
+	return nil
+}
+
+func writeJSONReports(w io.Writer, reports []*linter.Report, linterErrors []string) error {
+	type reportList struct {
+		Reports []*linter.Report
+		Errors  []string
+	}
+	list := &reportList{
+		Reports: reports,
+		Errors:  linterErrors,
+	}
+	return json.NewEncoder(w).Encode(list)
+}
@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// projectConfigNames are file names that are looked up in the project root
+// (and its parent directories) when -config is not specified.
+var projectConfigNames = []string{"noverify.json", ".noverify.json", ".noverify.yml", ".noverify.yaml"}
+
+// projectConfigPathFlags are flags that hold file paths.
+// Relative paths in a config file are resolved against the config file directory.
+var projectConfigPathFlags = map[string]bool{
+	"stubs-dir":      true,
+	"cache-dir":      true,
+	"baseline":       true,
+	"baseline-write": true,
+	"output":         true,
+	"git":            true,
+	"git-work-tree":  true,
+	"cpuprofile":     true,
+	"memprofile":     true,
+}
+
+// pathOverride describes checks settings for files that match the pathRegex.
+// Nil sets mean that the setting is not overridden.
+type pathOverride struct {
+	pathRegex *regexp.Regexp
+
+	includeChecksSet map[string]bool
+	excludeChecksSet map[string]bool
+
+	criticalOverridden bool
+	criticalSet        map[string]bool // nil means "all non-maybe"
+}
+
+var (
+	// projectConfigDir is a directory of the loaded project config.
+	// Override paths are matched against file names relative to it.
+	projectConfigDir string
+
+	pathOverrides []pathOverride
+)
+
+// loadProjectConfig finds project config and applies its settings.
+// Flags that are set explicitly in the command line are not changed.
+func loadProjectConfig() error {
+	filename := configFile
+	if filename == "" {
+		filename = findProjectConfig(projectRoot())
+		if filename == "" {
+			return nil
+		}
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var settings map[string]interface{}
+	switch filepath.Ext(filename) {
+	case ".yml", ".yaml":
+		settings, err = parseYAMLConfig(data)
+	default:
+		err = json.Unmarshal(data, &settings)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+
+	projectConfigDir, err = filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return err
+	}
+	if err := applyProjectConfig(settings); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	return nil
+}
+
+// projectRoot returns a directory that is expected to contain the project config.
+func projectRoot() string {
+	if gitRepo != "" {
+		return gitRepo
+	}
+	if flag.NArg() != 0 {
+		path := flag.Arg(0)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return filepath.Dir(path)
+		}
+		return path
+	}
+	return "."
+}
+
+// findProjectConfig looks for a config inside dir and all of its parents.
+// Returns empty string if nothing is found.
+func findProjectConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		for _, name := range projectConfigNames {
+			filename := filepath.Join(dir, name)
+			if _, err := os.Stat(filename); err == nil {
+				return filename
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func applyProjectConfig(settings map[string]interface{}) error {
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if key == "overrides" {
+			continue
+		}
+		// -version is handled while parsing the command line.
+		if key == "config" || key == "version" || flag.Lookup(key) == nil {
+			return fmt.Errorf("unknown setting %q", key)
+		}
+		if explicit[key] {
+			continue
+		}
+		value, err := configValueString(settings[key])
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		if projectConfigPathFlags[key] && value != "" && !filepath.IsAbs(value) {
+			value = filepath.Join(projectConfigDir, value)
+		}
+		if err := flag.Set(key, value); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+
+	if overrides, ok := settings["overrides"]; ok {
+		list, ok := overrides.([]interface{})
+		if !ok {
+			return fmt.Errorf("overrides: expected a list")
+		}
+		for i, o := range list {
+			m, ok := o.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("overrides[%d]: expected an object", i)
+			}
+			override, err := compilePathOverride(m, explicit)
+			if err != nil {
+				return fmt.Errorf("overrides[%d]: %v", i, err)
+			}
+			pathOverrides = append(pathOverrides, override)
+		}
+	}
+
+	return nil
+}
+
+func compilePathOverride(settings map[string]interface{}, explicit map[string]bool) (pathOverride, error) {
+	var o pathOverride
+	for key, v := range settings {
+		value, err := configValueString(v)
+		if err != nil {
+			return o, fmt.Errorf("%s: %v", key, err)
+		}
+		// Explicit flags have priority over any config setting.
+		if explicit[key] {
+			continue
+		}
+		switch key {
+		case "path":
+			o.pathRegex, err = regexp.Compile(value)
+			if err != nil {
+				return o, fmt.Errorf("incorrect path regex: %v", err)
+			}
+		case "allow-checks":
+			o.includeChecksSet = stringToSet(value)
+		case "exclude-checks":
+			o.excludeChecksSet = stringToSet(value)
+		case "critical":
+			o.criticalOverridden = true
+			if value != allNonMaybe {
+				o.criticalSet = stringToSet(value)
+			}
+		default:
+			return o, fmt.Errorf("setting %q can't be overridden per path", key)
+		}
+	}
+	if o.pathRegex == nil {
+		return o, fmt.Errorf("missing path")
+	}
+	return o, nil
+}
+
+// configValueString converts config value to a flag value.
+// Lists are converted into comma-separated strings.
+func configValueString(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, elem := range v {
+			s, err := configValueString(elem)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = s
+		}
+		return strings.Join(parts, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+// findPathOverride returns the last override that matches the filename.
+func findPathOverride(filename string) *pathOverride {
+	if len(pathOverrides) == 0 {
+		return nil
+	}
+	if abs, err := filepath.Abs(filename); err == nil {
+		if rel, err := filepath.Rel(projectConfigDir, abs); err == nil {
+			filename = rel
+		}
+	}
+	filename = filepath.ToSlash(filename)
+	for i := len(pathOverrides) - 1; i >= 0; i-- {
+		if pathOverrides[i].pathRegex.MatchString(filename) {
+			return &pathOverrides[i]
+		}
+	}
+	return nil
+}
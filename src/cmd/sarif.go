@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+)
+
+// SARIF 2.1.0 log subset that is sufficient to describe linter reports.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// sarifLevel maps linter report level to the SARIF result level.
+func sarifLevel(level int) string {
+	switch level {
+	case linter.LevelError, linter.LevelSyntax:
+		return "error"
+	case linter.LevelWarning, linter.LevelDoNotReject:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifURI converts a report filename to the artifact URI.
+// Relative paths are left relative, so they're resolved against the analysis root.
+func sarifURI(filename string) string {
+	uri := filepath.ToSlash(filename)
+	if filepath.IsAbs(filename) {
+		if !strings.HasPrefix(uri, "/") {
+			uri = "/" + uri // Windows drive letter paths
+		}
+		return "file://" + uri
+	}
+	return uri
+}
+
+func writeSarifReports(w io.Writer, reports []*linter.Report, linterErrors []string) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "NoVerify",
+				InformationURI: "https://github.com/Levsha-cc/noverify",
+			},
+		},
+		Invocations: []sarifInvocation{{ExecutionSuccessful: true}},
+		Results:     make([]sarifResult, 0, len(reports)),
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, info := range linter.GetDeclaredChecks() {
+		ruleIndex[info.Name] = len(run.Tool.Driver.Rules)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:               info.Name,
+			ShortDescription: &sarifMessage{Text: info.Comment},
+		})
+	}
+
+	for _, err := range linterErrors {
+		run.Invocations[0].ToolExecutionNotifications = append(run.Invocations[0].ToolExecutionNotifications, sarifNotification{
+			Level:   "error",
+			Message: sarifMessage{Text: err},
+		})
+	}
+
+	for _, r := range reports {
+		idx, ok := ruleIndex[r.CheckName()]
+		if !ok {
+			// Custom checks are not required to be declared.
+			idx = len(run.Tool.Driver.Rules)
+			ruleIndex[r.CheckName()] = idx
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: r.CheckName()})
+		}
+
+		region := sarifRegion{StartLine: r.Line()}
+		if r.Line() > 0 {
+			// SARIF columns are 1-based, end column is exclusive.
+			region.StartColumn = r.StartChar() + 1
+			if r.EndLine() > r.Line() {
+				region.EndLine = r.EndLine()
+				region.EndColumn = r.EndChar() + 1
+			} else if r.EndChar() > r.StartChar() {
+				region.EndColumn = r.EndChar() + 1
+			}
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    r.CheckName(),
+			RuleIndex: idx,
+			Level:     sarifLevel(r.Level()),
+			Message:   sarifMessage{Text: r.Message()},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: sarifURI(r.GetFilename())},
+					Region:           region,
+				},
+			}},
+			PartialFingerprints: map[string]string{
+				"noverifyFingerprint/v1": r.Fingerprint(),
+			},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	})
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// parseYAMLConfig parses a small subset of YAML that is enough for project configs:
+// block mappings and sequences, flow sequences of scalars ([a, b]),
+// plain, single and double quoted scalars and comments.
+//
+// The result has the same shape as json.Unmarshal output for an equivalent JSON.
+func parseYAMLConfig(data []byte) (map[string]interface{}, error) {
+	p := &yamlParser{}
+	for i, ln := range strings.Split(string(data), "\n") {
+		ln = strings.TrimRight(stripYAMLComment(ln), " \t\r")
+		text := strings.TrimLeft(ln, " ")
+		if text == "" || text == "---" {
+			continue
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		p.lines = append(p.lines, yamlLine{num: i + 1, indent: len(ln) - len(text), text: text})
+	}
+
+	if len(p.lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	v, err := p.parseBlock(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].num)
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a mapping at the top level")
+	}
+	return m, nil
+}
+
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+var yamlKeyRegex = regexp.MustCompile(`^([A-Za-z0-9_.-]+):(?: +(.*))?$`)
+
+func (p *yamlParser) parseBlock(indent int) (interface{}, error) {
+	if strings.HasPrefix(p.lines[p.pos].text, "-") {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+func (p *yamlParser) parseMapping(indent int) (interface{}, error) {
+	res := make(map[string]interface{})
+	for p.pos < len(p.lines) {
+		ln := p.lines[p.pos]
+		if ln.indent < indent {
+			break
+		}
+		if ln.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", ln.num)
+		}
+		m := yamlKeyRegex.FindStringSubmatch(ln.text)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: expected 'key: value'", ln.num)
+		}
+		key, value := m[1], m[2]
+		if _, ok := res[key]; ok {
+			return nil, fmt.Errorf("line %d: duplicated key %q", ln.num, key)
+		}
+		p.pos++
+
+		if value != "" {
+			v, err := parseYAMLScalar(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", ln.num, err)
+			}
+			res[key] = v
+			continue
+		}
+
+		// Nested block. Sequences are allowed to have the same indentation as the key.
+		if p.pos < len(p.lines) {
+			next := p.lines[p.pos]
+			if next.indent > indent || (next.indent == indent && strings.HasPrefix(next.text, "-")) {
+				v, err := p.parseBlock(next.indent)
+				if err != nil {
+					return nil, err
+				}
+				res[key] = v
+				continue
+			}
+		}
+		res[key] = ""
+	}
+	return res, nil
+}
+
+func (p *yamlParser) parseSequence(indent int) (interface{}, error) {
+	res := []interface{}{}
+	for p.pos < len(p.lines) {
+		ln := p.lines[p.pos]
+		if ln.indent != indent || !(ln.text == "-" || strings.HasPrefix(ln.text, "- ")) {
+			break
+		}
+		rest := strings.TrimLeft(ln.text[1:], " ")
+
+		switch {
+		case rest == "":
+			p.pos++
+			if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent {
+				res = append(res, "")
+				continue
+			}
+			v, err := p.parseBlock(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, v)
+
+		case yamlKeyRegex.MatchString(rest):
+			// "- key: value" starts a mapping that continues on the next lines
+			// with the same indentation as the key.
+			itemIndent := indent + len(ln.text) - len(rest)
+			p.lines[p.pos] = yamlLine{num: ln.num, indent: itemIndent, text: rest}
+			v, err := p.parseMapping(itemIndent)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, v)
+
+		default:
+			v, err := parseYAMLScalar(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", ln.num, err)
+			}
+			res = append(res, v)
+			p.pos++
+		}
+	}
+	return res, nil
+}
+
+func parseYAMLScalar(s string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("unterminated flow sequence")
+		}
+		res := []interface{}{}
+		body := strings.TrimSpace(s[1 : len(s)-1])
+		if body == "" {
+			return res, nil
+		}
+		parts, err := splitYAMLFlowSequence(body)
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range parts {
+			v, err := parseYAMLScalar(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, v)
+		}
+		return res, nil
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, `'`):
+		if len(s) < 2 || !strings.HasSuffix(s, `'`) {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		return strings.Replace(s[1:len(s)-1], `''`, `'`, -1), nil
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	case strings.HasPrefix(s, "{"):
+		return nil, fmt.Errorf("flow mappings are not supported")
+	default:
+		return s, nil
+	}
+}
+
+// splitYAMLFlowSequence splits flow sequence body into items.
+// Commas inside quoted items don't separate them.
+func splitYAMLFlowSequence(body string) ([]string, error) {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(body); i++ {
+		ch := body[i]
+		switch {
+		case quote != 0:
+			switch {
+			case ch == '\\' && quote == '"':
+				i++
+			case ch == '\'' && quote == '\'' && i+1 < len(body) && body[i+1] == '\'':
+				// '' is an escaped quote.
+				i++
+			case ch == quote:
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '[' || ch == '{':
+			return nil, fmt.Errorf("nested flow collections are not supported")
+		case ch == ',':
+			parts = append(parts, body[start:i])
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated string")
+	}
+	return append(parts, body[start:]), nil
+}
+
+// stripYAMLComment removes a trailing comment from the line.
+// Comment starts with '#' that is not a part of a quoted string
+// and is either the first char or preceded by a whitespace.
+func stripYAMLComment(ln string) string {
+	var quote byte
+	for i := 0; i < len(ln); i++ {
+		ch := ln[i]
+		switch {
+		case quote != 0:
+			switch {
+			case ch == '\\' && quote == '"':
+				i++
+			case ch == '\'' && quote == '\'' && i+1 < len(ln) && ln[i+1] == '\'':
+				// '' is an escaped quote.
+				i++
+			case ch == quote:
+				quote = 0
+			}
+		case (ch == '"' || ch == '\'') && (i == 0 || strings.IndexByte(" \t[,", ln[i-1]) != -1):
+			quote = ch
+		case ch == '#' && (i == 0 || ln[i-1] == ' ' || ln[i-1] == '\t'):
+			return ln[:i]
+		}
+	}
+	return ln
+}
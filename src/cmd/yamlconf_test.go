@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAMLConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[string]interface{}
+	}{
+		{
+			name: "empty",
+			in:   "# only a comment\n---\n",
+			want: map[string]interface{}{},
+		},
+		{
+			name: "scalars",
+			in: `allow-checks: unused,argCount
+cores: 4
+output-json: true
+unused-var-regex: '^_$'
+exclude: "vendor/\\w+"
+stubs-dir: ./stubs # comment
+`,
+			want: map[string]interface{}{
+				"allow-checks":     "unused,argCount",
+				"cores":            "4",
+				"output-json":      true,
+				"unused-var-regex": "^_$",
+				"exclude":          `vendor/\w+`,
+				"stubs-dir":        "./stubs",
+			},
+		},
+		{
+			name: "quotes",
+			in: `a: 'it''s # not a comment'
+b: "tab\tand # not a comment"
+c: value#not-a-comment
+`,
+			want: map[string]interface{}{
+				"a": "it's # not a comment",
+				"b": "tab\tand # not a comment",
+				"c": "value#not-a-comment",
+			},
+		},
+		{
+			name: "flow sequences",
+			in: `a: []
+b: [unused, argCount]
+c: ['a,b', "c,\"d", 'e''f,', plain]
+d: ['^(foo|bar){1,2}$', x]
+`,
+			want: map[string]interface{}{
+				"a": []interface{}{},
+				"b": []interface{}{"unused", "argCount"},
+				"c": []interface{}{"a,b", `c,"d`, "e'f,", "plain"},
+				"d": []interface{}{"^(foo|bar){1,2}$", "x"},
+			},
+		},
+		{
+			name: "block sequences",
+			in: `allow-checks:
+  - unused
+  - argCount
+exclude-checks:
+- phpdoc
+empty:
+`,
+			want: map[string]interface{}{
+				"allow-checks":   []interface{}{"unused", "argCount"},
+				"exclude-checks": []interface{}{"phpdoc"},
+				"empty":          "",
+			},
+		},
+		{
+			name: "sequence of mappings",
+			in: `overrides:
+  - path: tests/
+    exclude-checks: [unused]
+  - path: 'vendor/'
+    critical:
+      - undefined
+`,
+			want: map[string]interface{}{
+				"overrides": []interface{}{
+					map[string]interface{}{
+						"path":           "tests/",
+						"exclude-checks": []interface{}{"unused"},
+					},
+					map[string]interface{}{
+						"path":     "vendor/",
+						"critical": []interface{}{"undefined"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		have, err := parseYAMLConfig([]byte(test.in))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(have, test.want) {
+			t.Errorf("%s:\nhave: %#v\nwant: %#v", test.name, have, test.want)
+		}
+	}
+}
+
+func TestParseYAMLConfigErrors(t *testing.T) {
+	tests := []struct {
+		in  string
+		err string
+	}{
+		{"a: 1\na: 2\n", `line 2: duplicated key "a"`},
+		{"a: 1\n  b: 2\n", `line 2: unexpected indentation`},
+		{"a: 1\nb\n", `line 2: expected 'key: value'`},
+		{"a:\n\t- b\n", `line 2: tabs are not allowed for indentation`},
+		{"- a\n- b\n", `expected a mapping at the top level`},
+		{"a: [b, c\n", `line 1: unterminated flow sequence`},
+		{"a: [b, 'c]\n", `line 1: unterminated string`},
+		{"a: [b, [c]]\n", `line 1: nested flow collections are not supported`},
+		{"a: {b: c}\n", `line 1: flow mappings are not supported`},
+		{"a: 'b\n", `line 1: unterminated string`},
+	}
+
+	for _, test := range tests {
+		_, err := parseYAMLConfig([]byte(test.in))
+		if err == nil {
+			t.Errorf("%q: expected error %q, got nil", test.in, test.err)
+			continue
+		}
+		if err.Error() != test.err {
+			t.Errorf("%q: expected error %q, got %q", test.in, test.err, err.Error())
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package linter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Levsha-cc/noverify/src/meta"
+	"github.com/z7zmey/php-parser/node"
+	"github.com/z7zmey/php-parser/node/stmt"
+)
+
+// abstractMethod is a method that must be implemented by a concrete class.
+type abstractMethod struct {
+	className string
+	name      string
+}
+
+// checkUnimplementedMethods reports abstract methods of the parent classes,
+// interfaces and traits that are not implemented by the current class.
+func (d *RootWalker) checkUnimplementedMethods(nameNode node.Node) {
+	methods := make(map[string]abstractMethod)
+	collectAbstractMethods(d.st.CurrentClass, methods, make(map[string]struct{}))
+
+	keys := make([]string, 0, len(methods))
+	for key := range methods {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		m := methods[key]
+		if m.className == d.st.CurrentClass {
+			// Reported as an abstract method in non-abstract class.
+			continue
+		}
+		if hasMethodImpl(d.st.CurrentClass, m.name, make(map[string]struct{})) {
+			continue
+		}
+		d.Report(nameNode, LevelError, "unimplemented", "Class %s must implement %s::%s method", d.st.CurrentClass, m.className, m.name)
+	}
+}
+
+// collectAbstractMethods adds abstract methods declared in the class,
+// its parents, interfaces and traits to the methods map.
+func collectAbstractMethods(className string, methods map[string]abstractMethod, visited map[string]struct{}) {
+	if _, ok := visited[className]; ok {
+		return
+	}
+	visited[className] = struct{}{}
+
+	class, ok := meta.Info.GetClass(className)
+	if !ok {
+		class, ok = meta.Info.GetTrait(className)
+		if !ok {
+			return
+		}
+	}
+
+	for name, m := range class.Methods {
+		key := strings.ToLower(name)
+		if _, ok := methods[key]; ok || !m.Abstract {
+			continue
+		}
+		methods[key] = abstractMethod{className: className, name: name}
+	}
+
+	for _, trait := range sortedSet(class.Traits) {
+		collectAbstractMethods(trait, methods, visited)
+	}
+	for _, iface := range sortedSet(class.Interfaces) {
+		collectAbstractMethods(iface, methods, visited)
+	}
+	for _, iface := range class.ParentInterfaces {
+		collectAbstractMethods(iface, methods, visited)
+	}
+	if class.Parent != "" {
+		collectAbstractMethods(class.Parent, methods, visited)
+	}
+}
+
+// hasMethodImpl reports whether the class has a non-abstract method
+// declared in itself, its traits or parent classes.
+//
+// Unlike solver.FindMethod, all traits are inspected, because
+// an abstract trait method can be implemented by another trait.
+func hasMethodImpl(className, methodName string, visited map[string]struct{}) bool {
+	if _, ok := visited[className]; ok {
+		return false
+	}
+	visited[className] = struct{}{}
+
+	class, ok := meta.Info.GetClass(className)
+	if !ok {
+		class, ok = meta.Info.GetTrait(className)
+		if !ok {
+			return false
+		}
+	}
+
+	// Method names are case-insensitive.
+	for name, m := range class.Methods {
+		if strings.EqualFold(name, methodName) {
+			return !m.Abstract
+		}
+	}
+	for trait := range class.Traits {
+		if hasMethodImpl(trait, methodName, visited) {
+			return true
+		}
+	}
+	return class.Parent != "" && hasMethodImpl(class.Parent, methodName, visited)
+}
+
+func sortedSet(set map[string]struct{}) []string {
+	list := make([]string, 0, len(set))
+	for s := range set {
+		list = append(list, s)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func isAbstractClass(n node.Node) bool {
+	class, ok := n.(*stmt.Class)
+	if !ok {
+		return false
+	}
+	for _, m := range class.Modifiers {
+		if strings.EqualFold(m.(*node.Identifier).Value, "abstract") {
+			return true
+		}
+	}
+	return false
+}
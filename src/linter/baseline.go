@@ -0,0 +1,184 @@
+package linter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// baselineVersion is incremented when baseline file format changes incompatibly.
+const baselineVersion = 1
+
+// BaselineEntry describes a single known report.
+type BaselineEntry struct {
+	CheckName string `json:"check_name"`
+	Filename  string `json:"filename"`
+	Line      int    `json:"line"`
+	Context   string `json:"context"`
+}
+
+func (e *BaselineEntry) String() string {
+	return fmt.Sprintf("%s at %s:%d: %s", e.CheckName, e.Filename, e.Line, e.Context)
+}
+
+// Baseline is a set of known reports that should not be reported again.
+//
+// It's an alternative to DiffReports for projects that are not
+// using git (or can't use it for the analysis).
+type Baseline struct {
+	Version int             `json:"version"`
+	Entries []BaselineEntry `json:"entries"`
+}
+
+// baselineKey is what makes report and baseline entry identical.
+// Line number is not a part of the key, so edits above
+// the reported line don't make the report "new".
+type baselineKey struct {
+	checkName string
+	filename  string
+	context   string
+}
+
+// NewBaseline creates a baseline that contains all given reports.
+func NewBaseline(reports []*Report) *Baseline {
+	b := &Baseline{
+		Version: baselineVersion,
+		Entries: make([]BaselineEntry, 0, len(reports)),
+	}
+	for _, r := range reports {
+		b.Entries = append(b.Entries, newBaselineEntry(r))
+	}
+
+	// Make the output stable, so baseline files are VCS-friendly.
+	sort.SliceStable(b.Entries, func(i, j int) bool {
+		x, y := &b.Entries[i], &b.Entries[j]
+		if x.Filename != y.Filename {
+			return x.Filename < y.Filename
+		}
+		if x.Line != y.Line {
+			return x.Line < y.Line
+		}
+		return x.CheckName < y.CheckName
+	})
+
+	return b
+}
+
+// ReadBaseline decodes baseline that was previously written by Baseline.Write.
+func ReadBaseline(r io.Reader) (*Baseline, error) {
+	var b Baseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, err
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d (expected %d)", b.Version, baselineVersion)
+	}
+	return &b, nil
+}
+
+// Write encodes baseline into w.
+func (b *Baseline) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// Filter removes reports that are present in the baseline.
+//
+// Every baseline entry can suppress only one report, so
+// new occurrences of the same issue are still reported.
+// If there are several candidates, entry with the closest line is selected.
+//
+// Entries that matched nothing are returned as stale:
+// they can be removed from the baseline.
+func (b *Baseline) Filter(reports []*Report) (res []*Report, stale []BaselineEntry) {
+	candidates := make(map[baselineKey][]int)
+	for i := range b.Entries {
+		e := &b.Entries[i]
+		k := baselineKey{checkName: e.CheckName, filename: e.Filename, context: e.Context}
+		candidates[k] = append(candidates[k], i)
+	}
+
+	used := make([]bool, len(b.Entries))
+	matched := make([]bool, len(reports))
+
+	// Exact line matches go first, so reports that didn't move
+	// can't be stolen by some other (shifted) report with the same key.
+	for exact := 1; exact >= 0; exact-- {
+		for i, r := range reports {
+			if matched[i] {
+				continue
+			}
+			e := newBaselineEntry(r)
+			k := baselineKey{checkName: e.CheckName, filename: e.Filename, context: e.Context}
+			best := -1
+			bestDist := 0
+			for _, idx := range candidates[k] {
+				if used[idx] {
+					continue
+				}
+				dist := b.Entries[idx].Line - e.Line
+				if dist < 0 {
+					dist = -dist
+				}
+				if exact == 1 && dist != 0 {
+					continue
+				}
+				if best == -1 || dist < bestDist {
+					best = idx
+					bestDist = dist
+				}
+			}
+			if best != -1 {
+				used[best] = true
+				matched[i] = true
+			}
+		}
+	}
+
+	for i, r := range reports {
+		if !matched[i] {
+			res = append(res, r)
+		}
+	}
+	for i, e := range b.Entries {
+		if !used[i] {
+			stale = append(stale, e)
+		}
+	}
+
+	return res, stale
+}
+
+func newBaselineEntry(r *Report) BaselineEntry {
+	return BaselineEntry{
+		CheckName: r.checkName,
+		Filename:  baselineFilename(r.filename),
+		Line:      r.startLine,
+		Context:   normalizeContextLine(r.startLn),
+	}
+}
+
+// baselineFilename makes filename relative to the working directory (if possible),
+// so the baseline can be used on another machine or in another checkout location.
+func baselineFilename(filename string) string {
+	if filepath.IsAbs(filename) {
+		if wd, err := os.Getwd(); err == nil {
+			rel, err := filepath.Rel(wd, filename)
+			if err == nil && !strings.HasPrefix(rel, "..") {
+				filename = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filename)
+}
+
+// normalizeContextLine removes insignificant whitespace differences,
+// so re-indentation doesn't make the report look new.
+func normalizeContextLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -1,6 +1,7 @@
 package linter
 
 import (
+	"bytes"
 	"fmt"
 	"regexp"
 	"strings"
@@ -50,6 +51,11 @@ type BlockWalker struct {
 
 	// inferred return types if any
 	returnTypes *meta.TypesMap
+	// taint of the returned values, only computed if taint analysis is enabled
+	returnTaint meta.Taint
+
+	// declared return type, nil if return statements are not checked
+	funcReturn *funcReturnType
 
 	r *RootWalker
 
@@ -72,6 +78,15 @@ type BlockWalker struct {
 	// shared state between all blocks
 	unusedVars   map[string][]node.Node
 	nonLocalVars map[string]struct{} // static, global and other vars that have complex control flow
+
+	// params that are reported if unused, their variables are tracked in unusedVars
+	unusedParams *unusedParamsCheck
+	paramVars    map[node.Node]int // param variable nodes to their indexes
+
+	// whether narrowed types are only used inside the current expression
+	narrowExpr bool
+	// fetches that are allowed on null values, like the ones inside isset()
+	nullSafe map[node.Node]struct{}
 }
 
 func (b *BlockWalker) EnterChildNode(key string, w walker.Walkable) {}
@@ -178,17 +193,17 @@ func varToString(v *expr.Variable) string {
 	}
 }
 
-func (b *BlockWalker) checkRedundantCastArray(e node.Node) {
+func (b *BlockWalker) checkRedundantCastArray(c, e node.Node) {
 	if !meta.IsIndexingComplete() {
 		return
 	}
 	typ := solver.ExprType(b.ctx.sc, b.r.st, e)
 	if typ.Len() == 1 && typ.String() == "mixed[]" {
-		b.r.Report(e, LevelDoNotReject, "redundantCast", "expression already has array type")
+		b.r.ReportWithFix(e, LevelDoNotReject, "redundantCast", b.redundantCastFix(c, e), "expression already has array type")
 	}
 }
 
-func (b *BlockWalker) checkRedundantCast(e node.Node, dstType string) {
+func (b *BlockWalker) checkRedundantCast(c, e node.Node, dstType string) {
 	if !meta.IsIndexingComplete() {
 		return
 	}
@@ -198,12 +213,38 @@ func (b *BlockWalker) checkRedundantCast(e node.Node, dstType string) {
 	}
 	typ.Iterate(func(x string) {
 		if x == dstType {
-			b.r.Report(e, LevelDoNotReject, "redundantCast",
+			b.r.ReportWithFix(e, LevelDoNotReject, "redundantCast", b.redundantCastFix(c, e),
 				"expression already has %s type", dstType)
 		}
 	})
 }
 
+var castPrefixRegex = regexp.MustCompile(`^\(\s*[a-zA-Z]+\s*\)\s*$`)
+
+// redundantCastFix returns a fix that removes cast c from the expression e.
+//
+// Only simple expressions are handled, so there is no need
+// to worry about operators precedence and parenthesis.
+func (b *BlockWalker) redundantCastFix(c, e node.Node) []TextEdit {
+	switch e.(type) {
+	case *expr.Variable, *expr.PropertyFetch, *expr.StaticPropertyFetch, *expr.ArrayDimFetch,
+		*expr.FunctionCall, *expr.MethodCall, *expr.StaticCall, *expr.ConstFetch, *expr.ClassConstFetch,
+		*scalar.String, *scalar.Lnumber, *scalar.Dnumber:
+	default:
+		return nil
+	}
+
+	castStart, castEnd := nodeRange(c)
+	exprStart, exprEnd := nodeRange(e)
+	if castEnd != exprEnd || exprStart < castStart || exprEnd > len(b.r.fileContents) {
+		return nil
+	}
+	if !castPrefixRegex.Match(b.r.fileContents[castStart:exprStart]) {
+		return nil
+	}
+	return []TextEdit{ReplaceNode(c, string(b.r.fileContents[exprStart:exprEnd]))}
+}
+
 // EnterNode is called before walking to inner nodes.
 func (b *BlockWalker) EnterNode(w walker.Walkable) (res bool) {
 	res = true
@@ -226,6 +267,17 @@ func (b *BlockWalker) EnterNode(w walker.Walkable) (res bool) {
 		}
 	}
 
+	if b.r.classMembers != nil {
+		b.r.classMembers.markUsed(w)
+	}
+	if b.r.symbols != nil {
+		b.r.symbols.markUsed(w)
+	}
+	if TaintAnalysis {
+		b.handleTaint(n)
+	}
+	b.checkDuplicates(n)
+
 	switch s := w.(type) {
 	case *binary.BitwiseAnd:
 		b.handleBitwiseAnd(s)
@@ -233,15 +285,15 @@ func (b *BlockWalker) EnterNode(w walker.Walkable) (res bool) {
 		b.handleBitwiseOr(s)
 
 	case *cast.Double:
-		b.checkRedundantCast(s.Expr, "float")
+		b.checkRedundantCast(s, s.Expr, "float")
 	case *cast.Int:
-		b.checkRedundantCast(s.Expr, "int")
+		b.checkRedundantCast(s, s.Expr, "int")
 	case *cast.Bool:
-		b.checkRedundantCast(s.Expr, "bool")
+		b.checkRedundantCast(s, s.Expr, "bool")
 	case *cast.String:
-		b.checkRedundantCast(s.Expr, "string")
+		b.checkRedundantCast(s, s.Expr, "string")
 	case *cast.Array:
-		b.checkRedundantCastArray(s.Expr)
+		b.checkRedundantCastArray(s, s.Expr)
 	case *stmt.Global:
 		for _, v := range s.Vars {
 			ev := v.(*expr.Variable)
@@ -341,6 +393,7 @@ func (b *BlockWalker) EnterNode(w walker.Walkable) (res bool) {
 		res = b.handleVariable(s)
 	case *expr.ArrayDimFetch:
 		b.checkArrayDimFetch(s)
+		b.checkNullableDimFetch(s)
 	case *stmt.Function:
 		res = b.handleFunction(s)
 	case *stmt.Class:
@@ -368,6 +421,14 @@ func (b *BlockWalker) EnterNode(w walker.Walkable) (res bool) {
 		b.handleContinue(s)
 	case *binary.LogicalOr:
 		res = b.handleLogicalOr(s)
+	case *binary.BooleanAnd:
+		res = b.handleBooleanAnd(s)
+	case *binary.BooleanOr:
+		res = b.handleBooleanOr(s)
+	case *binary.Coalesce:
+		res = b.handleCoalesce(s)
+	case *expr.Ternary:
+		res = b.handleTernary(s)
 	default:
 		// b.d.debug(`  Statement: %T`, s)
 	}
@@ -388,6 +449,8 @@ func (b *BlockWalker) handleFunction(fun *stmt.Function) bool {
 }
 
 func (b *BlockWalker) handleReturn(ret *stmt.Return) {
+	b.checkReturnType(ret)
+
 	if ret.Expr == nil {
 		// Return without explicit return value.
 		b.bareReturn = true
@@ -399,6 +462,75 @@ func (b *BlockWalker) handleReturn(ret *stmt.Return) {
 	typ.Iterate(func(t string) {
 		b.returnTypes = b.returnTypes.AppendString(t)
 	})
+
+	if TaintAnalysis {
+		b.returnTaint |= b.exprTaint(ret.Expr)
+	}
+}
+
+// declaredReturnType returns resolved declared return type of the current function.
+// Returns nil if return statements should not be checked.
+func (b *BlockWalker) declaredReturnType() map[string]struct{} {
+	if b.funcReturn == nil || b.funcReturn.ctor || !meta.IsIndexingComplete() || b.r.st.IsTrait {
+		return nil
+	}
+	return resolveTypesStrict(b.r.st.CurrentClass, b.funcReturn.typ)
+}
+
+func (b *BlockWalker) checkReturnType(ret *stmt.Return) {
+	fn := b.funcReturn
+	if fn == nil || !meta.IsIndexingComplete() {
+		return
+	}
+
+	if fn.ctor || fn.typ.Is("void") {
+		switch {
+		case ret.Expr == nil:
+		case fn.ctor:
+			b.r.Report(ret.Expr, LevelWarning, "returnType", "Constructor %s must not return a value", fn.funcName)
+		default:
+			b.r.Report(ret.Expr, LevelWarning, "returnType", "Void function %s must not return a value", fn.funcName)
+		}
+		return
+	}
+
+	want := b.declaredReturnType()
+	if want == nil {
+		return
+	}
+
+	if ret.Expr == nil {
+		if !typesCompatible(want, nullType) {
+			b.r.Report(ret, LevelWarning, "returnType", "Return value of %s must be of type %s, none returned", fn.funcName, formatTypes(want))
+		}
+		return
+	}
+
+	have := resolveTypesStrict(b.r.st.CurrentClass, solver.ExprTypeLocalCustom(b.ctx.sc, b.r.st, ret.Expr, b.ctx.customTypes))
+	if have == nil || typesCompatible(want, have) {
+		return
+	}
+	b.r.Report(ret.Expr, LevelWarning, "returnType", "Return value of %s must be of type %s, %s returned",
+		fn.funcName, formatTypes(want), formatTypes(have))
+}
+
+// checkMissingReturn reports non-void functions that can reach the end of their body.
+func (b *BlockWalker) checkMissingReturn(stmts []node.Node) {
+	// Empty bodies are common for stubs that only describe the signature.
+	if b.funcReturn == nil || b.funcReturn.typ.Is("void") || b.ctx.exitFlags != 0 || len(stmts) == 0 {
+		return
+	}
+	switch stmts[len(stmts)-1].(type) {
+	case *stmt.While, *stmt.AltWhile, *stmt.Do, *stmt.For, *stmt.AltFor:
+		// Could be an infinite loop that is left by return only.
+		return
+	}
+
+	want := b.declaredReturnType()
+	if want == nil || typesCompatible(want, nullType) {
+		return
+	}
+	b.r.Report(b.funcReturn.nameNode, LevelWarning, "returnType", "Missing return statement: %s must return %s", b.funcReturn.funcName, formatTypes(want))
 }
 
 func (b *BlockWalker) handleLogicalOr(or *binary.LogicalOr) bool {
@@ -412,6 +544,42 @@ func (b *BlockWalker) handleLogicalOr(or *binary.LogicalOr) bool {
 	return false
 }
 
+func (b *BlockWalker) handleBooleanAnd(and *binary.BooleanAnd) bool {
+	and.Left.Walk(b)
+	b.withNarrowed(and.Left, true, func() {
+		and.Right.Walk(b)
+	})
+	return false
+}
+
+func (b *BlockWalker) handleBooleanOr(or *binary.BooleanOr) bool {
+	or.Left.Walk(b)
+	b.withNarrowed(or.Left, false, func() {
+		or.Right.Walk(b)
+	})
+	return false
+}
+
+func (b *BlockWalker) handleCoalesce(c *binary.Coalesce) bool {
+	b.markNullSafe(c.Left)
+	c.Left.Walk(b)
+	c.Right.Walk(b)
+	return false
+}
+
+func (b *BlockWalker) handleTernary(t *expr.Ternary) bool {
+	t.Condition.Walk(b)
+	if t.IfTrue != nil {
+		b.withNarrowed(t.Condition, true, func() {
+			t.IfTrue.Walk(b)
+		})
+	}
+	b.withNarrowed(t.Condition, false, func() {
+		t.IfFalse.Walk(b)
+	})
+	return false
+}
+
 func (b *BlockWalker) handleContinue(s *stmt.Continue) {
 	if s.Expr == nil && b.ctx.innermostLoop == loopSwitch {
 		b.r.Report(s, LevelError, "caseContinue", "'continue' inside switch is 'break'")
@@ -525,6 +693,7 @@ func (b *BlockWalker) handleUnset(s *stmt.Unset) bool {
 
 func (b *BlockWalker) handleIsset(s *expr.Isset) bool {
 	for _, v := range s.Variables {
+		b.markNullSafe(v)
 		switch v := v.(type) {
 		case *expr.Variable:
 			if id, ok := v.VarName.(*node.Identifier); ok {
@@ -543,6 +712,7 @@ func (b *BlockWalker) handleIsset(s *expr.Isset) bool {
 }
 
 func (b *BlockWalker) handleEmpty(s *expr.Empty) bool {
+	b.markNullSafe(s.Expr)
 	switch v := s.Expr.(type) {
 	case *expr.Variable:
 		if id, ok := v.VarName.(*node.Identifier); ok {
@@ -574,6 +744,10 @@ func (b *BlockWalker) withNewContext(action func()) *blockContext {
 	action()
 	b.ctx = oldCtx
 
+	// Values that could be assigned in the new context
+	// can be seen after it.
+	b.ctx.sc.MergeTaint(newCtx.sc)
+
 	return newCtx
 }
 
@@ -729,7 +903,23 @@ func (b *BlockWalker) enoughArgs(args []node.Node, fn meta.FuncInfo) bool {
 	return true
 }
 
-func (b *BlockWalker) handleArgsCount(n node.Node, args []node.Node, fn meta.FuncInfo) {
+func (b *BlockWalker) tooManyArgs(args []node.Node, fn meta.FuncInfo) bool {
+	if fn.Variadic || len(args) <= len(fn.Params) {
+		return false
+	}
+	// Unpacked ...$arg can be empty, so count only arguments before it.
+	for i, arg := range args {
+		if arg.(*node.Argument).Variadic {
+			return i > len(fn.Params)
+		}
+	}
+	return true
+}
+
+// handleArgsCount reports calls with wrong number of arguments.
+// Surplus arguments are only reported if fn is resolved, i.e. it's
+// the actual callee and not a zero value or a magic method fallback.
+func (b *BlockWalker) handleArgsCount(n node.Node, args []node.Node, fn meta.FuncInfo, resolved bool) {
 	switch {
 	case meta.NameNodeEquals(n, "mt_rand"):
 		if len(args) != 0 && len(args) != 2 {
@@ -741,10 +931,63 @@ func (b *BlockWalker) handleArgsCount(n node.Node, args []node.Node, fn meta.Fun
 	if !b.enoughArgs(args, fn) {
 		b.r.Report(n, LevelWarning, "argCount", "Too few arguments for %s", meta.NameNodeToString(n))
 	}
+	if resolved && b.tooManyArgs(args, fn) {
+		b.r.Report(n, LevelWarning, "argCount", "Too many arguments for %s", meta.NameNodeToString(n))
+	}
+}
+
+// checkArgTypes reports arguments that can't be passed to
+// the parameters with declared types.
+func (b *BlockWalker) checkArgTypes(fnName string, args []node.Node, fn meta.FuncInfo) {
+	if !meta.IsIndexingComplete() {
+		return
+	}
+
+	for i, arg := range args {
+		a := arg.(*node.Argument)
+		if a.Variadic {
+			// Can't match unpacked arguments with parameters.
+			return
+		}
+
+		var param meta.FuncParam
+		switch {
+		case i < len(fn.Params):
+			param = fn.Params[i]
+		case len(fn.Params) != 0 && fn.Params[len(fn.Params)-1].IsVariadic:
+			param = fn.Params[len(fn.Params)-1]
+		default:
+			return
+		}
+		if param.IsRef || !param.TypDeclared {
+			continue
+		}
+
+		want := resolveTypesStrict("", param.Typ)
+		if want == nil {
+			continue
+		}
+		if param.IsVariadic {
+			elemTypes := make(map[string]struct{}, len(want))
+			for typ := range want {
+				elemTypes[strings.TrimSuffix(typ, "[]")] = struct{}{}
+			}
+			want = elemTypes
+		}
+
+		have := resolveTypesStrict(b.r.st.CurrentClass, solver.ExprTypeLocalCustom(b.ctx.sc, b.r.st, a.Expr, b.ctx.customTypes))
+		if have == nil || typesCompatible(want, have) {
+			continue
+		}
+
+		b.r.Report(a.Expr, LevelWarning, "argType", "Argument %d passed to %s must be of type %s, %s given",
+			i+1, fnName, formatTypes(want), formatTypes(have))
+	}
 }
 
-func (b *BlockWalker) handleCallArgs(n node.Node, args []node.Node, fn meta.FuncInfo) {
-	b.handleArgsCount(n, args, fn)
+func (b *BlockWalker) handleCallArgs(n node.Node, args []node.Node, fn meta.FuncInfo, resolved bool) {
+	b.handleArgsCount(n, args, fn, resolved)
+	b.checkArgTypes(meta.NameNodeToString(n), args, fn)
 
 	for i, arg := range args {
 		if i >= len(fn.Params) {
@@ -777,36 +1020,15 @@ func (b *BlockWalker) handleCallArgs(n node.Node, args []node.Node, fn meta.Func
 func (b *BlockWalker) handleFunctionCall(e *expr.FunctionCall) bool {
 	var fn meta.FuncInfo
 	var fqName string
+	defined := false
 
 	if meta.IsIndexingComplete() {
-		defined := true
+		defined = true
 		canAnalyze := true
 
 		switch nm := e.Function.(type) {
-		case *name.Name:
-			nameStr := meta.NameToString(nm)
-			firstPart := nm.Parts[0].(*name.NamePart).Value
-			if alias, ok := b.r.st.FunctionUses[firstPart]; ok {
-				if len(nm.Parts) == 1 {
-					nameStr = alias
-				} else {
-					// handle situations like 'use NS\Foo; Foo\Bar::doSomething();'
-					nameStr = alias + `\` + meta.NamePartsToString(nm.Parts[1:])
-				}
-				fqName = nameStr
-				fn, defined = meta.Info.GetFunction(fqName)
-			} else {
-				fqName = b.r.st.Namespace + `\` + nameStr
-				fn, defined = meta.Info.GetFunction(fqName)
-				if !defined && b.r.st.Namespace != "" {
-					fqName = `\` + nameStr
-					fn, defined = meta.Info.GetFunction(fqName)
-				}
-			}
-
-		case *name.FullyQualified:
-			fqName = meta.FullyQualifiedToString(nm)
-			fn, defined = meta.Info.GetFunction(fqName)
+		case *name.Name, *name.FullyQualified:
+			fqName, fn, defined = b.getFunction(nm)
 		default:
 			defined = false
 
@@ -841,18 +1063,54 @@ func (b *BlockWalker) handleFunctionCall(e *expr.FunctionCall) bool {
 		}
 	}
 
+	b.checkFormatCall(meta.NameNodeToString(e.Function), fqName, fn, e.ArgumentList.Arguments)
+	b.checkRegexpCall(fqName, e.ArgumentList.Arguments)
+
 	e.Function.Walk(b)
 
 	if fqName == `\compact` {
 		b.handleCompactCallArgs(e.ArgumentList.Arguments)
 	} else {
-		b.handleCallArgs(e.Function, e.ArgumentList.Arguments, fn)
+		b.handleCallArgs(e.Function, e.ArgumentList.Arguments, fn, defined)
 	}
 	b.ctx.exitFlags |= fn.ExitFlags
 
 	return false
 }
 
+// getFunction returns a fully qualified name of the function called by name nm
+// and its info if the function is defined.
+func (b *BlockWalker) getFunction(nm node.Node) (fqName string, fn meta.FuncInfo, defined bool) {
+	switch nm := nm.(type) {
+	case *name.Name:
+		nameStr := meta.NameToString(nm)
+		firstPart := nm.Parts[0].(*name.NamePart).Value
+		if alias, ok := b.r.st.FunctionUses[firstPart]; ok {
+			if len(nm.Parts) == 1 {
+				nameStr = alias
+			} else {
+				// handle situations like 'use NS\Foo; Foo\Bar::doSomething();'
+				nameStr = alias + `\` + meta.NamePartsToString(nm.Parts[1:])
+			}
+			fqName = nameStr
+			fn, defined = meta.Info.GetFunction(fqName)
+		} else {
+			fqName = b.r.st.Namespace + `\` + nameStr
+			fn, defined = meta.Info.GetFunction(fqName)
+			if !defined && b.r.st.Namespace != "" {
+				fqName = `\` + nameStr
+				fn, defined = meta.Info.GetFunction(fqName)
+			}
+		}
+
+	case *name.FullyQualified:
+		fqName = meta.FullyQualifiedToString(nm)
+		fn, defined = meta.Info.GetFunction(fqName)
+	}
+
+	return fqName, fn, defined
+}
+
 // handleCompactCallArgs treats strings anywhere in the argument list as uses
 // of the variables named by those strings, which is how compact() behaves.
 func (b *BlockWalker) handleCompactCallArgs(args []node.Node) {
@@ -959,6 +1217,8 @@ func (b *BlockWalker) handleMethodCall(e *expr.MethodCall) bool {
 	e.Variable.Walk(b)
 	e.Method.Walk(b)
 
+	b.checkNullableMethodCall(e, methodName, exprType)
+
 	if !foundMethod && !magic && !b.r.st.IsTrait && !b.isThisInsideClosure(e.Variable) {
 		b.r.Report(e.Method, LevelError, "undefined", "Call to undefined method {%s}->%s()", exprType, methodName)
 	} else {
@@ -983,7 +1243,11 @@ func (b *BlockWalker) handleMethodCall(e *expr.MethodCall) bool {
 		b.r.Report(e.Method, LevelError, "accessLevel", "Cannot access %s method %s->%s()", fn.AccessLevel, implClass, methodName)
 	}
 
-	b.handleCallArgs(e.Method, e.ArgumentList.Arguments, fn)
+	b.checkFormatCall(methodName, "", fn, e.ArgumentList.Arguments)
+
+	// __call is invoked instead of the found method if it is not accessible.
+	resolved := foundMethod && (!magic || b.canAccess(implClass, fn.AccessLevel))
+	b.handleCallArgs(e.Method, e.ArgumentList.Arguments, fn, resolved)
 	b.ctx.exitFlags |= fn.ExitFlags
 
 	return false
@@ -1031,7 +1295,10 @@ func (b *BlockWalker) handleStaticCall(e *expr.StaticCall) bool {
 		b.r.Report(e.Call, LevelError, "accessLevel", "Cannot access %s method %s::%s()", fn.AccessLevel, implClass, methodName)
 	}
 
-	b.handleCallArgs(e.Call, e.ArgumentList.Arguments, fn)
+	b.checkFormatCall(methodName, "", fn, e.ArgumentList.Arguments)
+
+	resolved := ok && (!magic || b.canAccess(implClass, fn.AccessLevel))
+	b.handleCallArgs(e.Call, e.ArgumentList.Arguments, fn, resolved)
 	b.ctx.exitFlags |= fn.ExitFlags
 
 	return false
@@ -1081,6 +1348,8 @@ func (b *BlockWalker) handlePropertyFetch(e *expr.PropertyFetch) bool {
 		magic = haveMagicMethod(className, `__get`)
 	})
 
+	b.checkNullablePropertyFetch(e, id.Value, typ)
+
 	if !found && !magic && !b.r.st.IsTrait && !b.isThisInsideClosure(e.Variable) {
 		b.r.Report(e.Property, LevelError, "undefined", "Property {%s}->%s does not exist", typ, id.Value)
 	}
@@ -1128,7 +1397,7 @@ func (b *BlockWalker) handleStaticPropertyFetch(e *expr.StaticPropertyFetch) boo
 }
 
 func (b *BlockWalker) handleArray(arr *expr.Array) bool {
-	b.r.Report(arr, LevelDoNotReject, "arraySyntax", "Use of old array syntax (use short form instead)")
+	b.r.ReportWithFix(arr, LevelDoNotReject, "arraySyntax", b.r.shortArraySyntaxFix(arr), "Use of old array syntax (use short form instead)")
 	return b.handleArrayItems(arr, arr.Items)
 }
 
@@ -1273,8 +1542,20 @@ func (b *BlockWalker) handleNew(e *expr.New) bool {
 		return true
 	}
 
-	if _, ok := meta.Info.GetClass(className); !ok {
+	class, ok := meta.Info.GetClass(className)
+	switch {
+	case !ok:
+		if _, ok := meta.Info.GetTrait(className); ok {
+			b.r.Report(e.Class, LevelError, "newAbstract", "Cannot instantiate trait %s", className)
+			return true
+		}
 		b.r.Report(e.Class, LevelError, "undefined", "Class not found %s", className)
+	case meta.NameNodeEquals(e.Class, "static"):
+		// Late static binding refers to a concrete child class.
+	case class.IsInterface:
+		b.r.Report(e.Class, LevelError, "newAbstract", "Cannot instantiate interface %s", className)
+	case class.Abstract:
+		b.r.Report(e.Class, LevelError, "newAbstract", "Cannot instantiate abstract class %s", className)
 	}
 
 	// Check implicitly invoked constructor method arguments count.
@@ -1291,6 +1572,10 @@ func (b *BlockWalker) handleNew(e *expr.New) bool {
 	if ok && !b.enoughArgs(args, ctor) {
 		b.r.Report(e, LevelError, "argCount", "Too few arguments for %s constructor", className)
 	}
+	if b.tooManyArgs(args, ctor) {
+		b.r.Report(e, LevelWarning, "argCount", "Too many arguments for %s constructor", className)
+	}
+	b.checkArgTypes(className+" constructor", args, ctor)
 
 	return true
 }
@@ -1478,13 +1763,22 @@ func (b *BlockWalker) enterClosure(fun *expr.Closure, haveThis bool, thisType *m
 		if ok {
 			sc.AddVarName(varName, typ, "use", true)
 		}
+		if TaintAnalysis {
+			sc.SetVarTaint(varName, b.ctx.sc.GetVarTaint(varName))
+		}
 
 		delete(b.unusedVars, varName)
 	}
 
 	params, _ := b.r.parseFuncArgs(fun.Params, phpDocParamTypes, sc)
 
-	b.r.handleFuncStmts(params, closureUses, fun.Stmts, sc)
+	unused := newUnusedParamsCheck(fun.Params, fun.Stmts)
+	if unused != nil {
+		// Closures are usually callbacks that have to accept
+		// the params in the order they are passed.
+		unused.positional = true
+	}
+	b.r.handleFuncStmts(params, closureUses, fun.Stmts, sc, nil, unused)
 	b.r.addScope(fun, sc)
 
 	return false
@@ -1597,7 +1891,13 @@ type andWalker struct {
 func (a *andWalker) EnterNode(w walker.Walkable) (res bool) {
 	switch n := w.(type) {
 	case *binary.BooleanAnd:
-		return true
+		// The node itself is not walked by the block walker.
+		a.b.checkDuplicates(n)
+		n.Left.Walk(a)
+		a.b.withNarrowed(n.Left, true, func() {
+			n.Right.Walk(a)
+		})
+		return false
 
 	case *expr.Isset:
 		for _, v := range n.Variables {
@@ -1701,25 +2001,37 @@ func (b *BlockWalker) handleIf(s *stmt.If) bool {
 
 	var contexts []*blockContext
 
-	walk := func(n node.Node) (links int) {
+	// Conditions of the walked branches are false in the next branches.
+	var prevConds []node.Node
+
+	// walk handles a branch that is executed when cond is true.
+	// Missing branches (n is nil) are walked too, so the types narrowed
+	// by the conditions are visible after the if statement.
+	walk := func(n, cond node.Node) (links int) {
 		// handle if (...) smth(); else other_thing(); // without braces
 		if els, ok := n.(*stmt.Else); ok {
 			b.addStatement(els.Stmt)
 		} else if elsif, ok := n.(*stmt.ElseIf); ok {
 			b.addStatement(elsif.Stmt)
-		} else {
+		} else if n != nil {
 			b.addStatement(n)
 		}
 
 		ctx := b.withNewContext(func() {
-			if elsif, ok := n.(*stmt.ElseIf); ok {
-				walkCond(elsif.Cond)
+			if elsifCond := elseIfCond(n); elsifCond != nil {
+				walkCond(elsifCond)
+			}
+			b.narrowBranch(prevConds, cond)
+			if n != nil {
+				n.Walk(b)
+				b.r.addScope(n, b.ctx.sc)
 			}
-			n.Walk(b)
-			b.r.addScope(n, b.ctx.sc)
 		})
 
 		contexts = append(contexts, ctx)
+		if cond != nil {
+			prevConds = append(prevConds, cond)
+		}
 
 		if ctx.exitFlags != 0 {
 			return 0
@@ -1728,25 +2040,16 @@ func (b *BlockWalker) handleIf(s *stmt.If) bool {
 		return 1
 	}
 
-	linksCount := 0
-
-	if s.Stmt != nil {
-		linksCount += walk(s.Stmt)
-	} else {
-		linksCount++
-	}
+	linksCount := walk(s.Stmt, s.Cond)
 
 	for _, n := range s.ElseIf {
-		linksCount += walk(n)
+		linksCount += walk(n, elseIfCond(n))
 	}
 
-	if s.Else != nil {
-		linksCount += walk(s.Else)
-	} else {
-		linksCount++
-	}
+	linksCount += walk(s.Else, nil)
 
 	b.propagateFlagsFromBranches(contexts, linksCount)
+	b.mergeBranchProperties(contexts)
 
 	varTypes := make(map[string]*meta.TypesMap, b.ctx.sc.Len())
 	defCounts := make(map[string]int, b.ctx.sc.Len())
@@ -1764,13 +2067,26 @@ func (b *BlockWalker) handleIf(s *stmt.If) bool {
 		})
 	}
 
+	// Every way to get past the if statement is represented by a context,
+	// so the merged types replace the types narrowed in the conditions.
 	for nm, types := range varTypes {
-		b.ctx.sc.AddVarName(nm, types, "all branches", defCounts[nm] == linksCount)
+		b.ctx.sc.NarrowVarName(nm, types, "all branches", defCounts[nm] == linksCount)
 	}
 
 	return false
 }
 
+// elseIfCond returns a condition of elseif branch n or nil if n is not an elseif.
+func elseIfCond(n node.Node) node.Node {
+	switch n := n.(type) {
+	case *stmt.ElseIf:
+		return n.Cond
+	case *stmt.AltElseIf:
+		return n.Cond
+	}
+	return nil
+}
+
 func (b *BlockWalker) handleAltIf(s *stmt.AltIf) bool {
 	var varsToDelete []*expr.Variable
 	// Remove all isset'ed variables after we're finished with this if statement.
@@ -1792,25 +2108,37 @@ func (b *BlockWalker) handleAltIf(s *stmt.AltIf) bool {
 
 	var contexts []*blockContext
 
-	walk := func(n node.Node) (links int) {
+	// Conditions of the walked branches are false in the next branches.
+	var prevConds []node.Node
+
+	// walk handles a branch that is executed when cond is true.
+	// Missing branches (n is nil) are walked too, so the types narrowed
+	// by the conditions are visible after the if statement.
+	walk := func(n, cond node.Node) (links int) {
 		// handle if (...) smth(); else other_thing(); // without braces
 		if els, ok := n.(*stmt.Else); ok {
 			b.addStatement(els.Stmt)
 		} else if elsif, ok := n.(*stmt.ElseIf); ok {
 			b.addStatement(elsif.Stmt)
-		} else {
+		} else if n != nil {
 			b.addStatement(n)
 		}
 
 		ctx := b.withNewContext(func() {
-			if elsif, ok := n.(*stmt.ElseIf); ok {
-				walkCond(elsif.Cond)
+			if elsifCond := elseIfCond(n); elsifCond != nil {
+				walkCond(elsifCond)
+			}
+			b.narrowBranch(prevConds, cond)
+			if n != nil {
+				n.Walk(b)
+				b.r.addScope(n, b.ctx.sc)
 			}
-			n.Walk(b)
-			b.r.addScope(n, b.ctx.sc)
 		})
 
 		contexts = append(contexts, ctx)
+		if cond != nil {
+			prevConds = append(prevConds, cond)
+		}
 
 		if ctx.exitFlags != 0 {
 			return 0
@@ -1819,25 +2147,16 @@ func (b *BlockWalker) handleAltIf(s *stmt.AltIf) bool {
 		return 1
 	}
 
-	linksCount := 0
-
-	if s.Stmt != nil {
-		linksCount += walk(s.Stmt)
-	} else {
-		linksCount++
-	}
+	linksCount := walk(s.Stmt, s.Cond)
 
 	for _, n := range s.ElseIf {
-		linksCount += walk(n)
+		linksCount += walk(n, elseIfCond(n))
 	}
 
-	if s.Else != nil {
-		linksCount += walk(s.Else)
-	} else {
-		linksCount++
-	}
+	linksCount += walk(s.Else, nil)
 
 	b.propagateFlagsFromBranches(contexts, linksCount)
+	b.mergeBranchProperties(contexts)
 
 	varTypes := make(map[string]*meta.TypesMap, b.ctx.sc.Len())
 	defCounts := make(map[string]int, b.ctx.sc.Len())
@@ -1855,8 +2174,10 @@ func (b *BlockWalker) handleAltIf(s *stmt.AltIf) bool {
 		})
 	}
 
+	// Every way to get past the if statement is represented by a context,
+	// so the merged types replace the types narrowed in the conditions.
 	for nm, types := range varTypes {
-		b.ctx.sc.AddVarName(nm, types, "all branches", defCounts[nm] == linksCount)
+		b.ctx.sc.NarrowVarName(nm, types, "all branches", defCounts[nm] == linksCount)
 	}
 
 	return false
@@ -1937,7 +2258,7 @@ func (b *BlockWalker) handleSwitch(s *stmt.Switch) bool {
 				// allow the fallthrough if appropriate comment is present
 				nextCase := s.CaseList.Cases[idx+1]
 				if !b.caseHasFallthroughComment(nextCase) {
-					b.r.Report(c, LevelInformation, "caseBreak", "Add break or '// fallthrough' to the end of the case")
+					b.reportCaseBreak(c, nextCase)
 				}
 			}
 
@@ -2047,7 +2368,7 @@ func (b *BlockWalker) handleAltSwitch(s *stmt.AltSwitch) bool {
 				// allow the fallthrough if appropriate comment is present
 				nextCase := s.CaseList.Cases[idx+1]
 				if !b.caseHasFallthroughComment(nextCase) {
-					b.r.Report(c, LevelInformation, "caseBreak", "Add break or '// fallthrough' to the end of the case")
+					b.reportCaseBreak(c, nextCase)
 				}
 			}
 
@@ -2204,12 +2525,14 @@ func (b *BlockWalker) handleAssign(a *assign.Assign) bool {
 		b.handleDimFetchLValue(v, "assign_array", typ)
 		return false
 	case *expr.Variable:
-		b.replaceVar(v, solver.ExprTypeLocal(b.ctx.sc, b.r.st, a.Expression), "assign", true)
+		b.replaceVar(v, b.exprTypeNarrowed(a.Expression), "assign", true)
 	case *expr.List:
 		b.handleAssignList(v.Items)
 	case *expr.ShortList:
 		b.handleAssignList(v.Items)
 	case *expr.PropertyFetch:
+		b.assignProperty(v, b.exprTypeNarrowed(a.Expression))
+
 		varNode, ok := v.Variable.(*expr.Variable)
 		if !ok {
 			v.Variable.Walk(b)
@@ -2246,6 +2569,8 @@ func (b *BlockWalker) handleAssign(a *assign.Assign) bool {
 		p.Typ = p.Typ.Append(solver.ExprTypeLocalCustom(b.ctx.sc, b.r.st, a.Expression, b.ctx.customTypes))
 		cls.Properties[propertyName.Value] = p
 	case *expr.StaticPropertyFetch:
+		b.assignProperty(v, b.exprTypeNarrowed(a.Expression))
+
 		varNode, ok := v.Property.(*expr.Variable)
 		if !ok {
 			break
@@ -2283,6 +2608,8 @@ func (b *BlockWalker) flushUnused() {
 		return
 	}
 
+	b.flushUnusedParams()
+
 	visitedMap := make(map[node.Node]struct{})
 	for name, nodes := range b.unusedVars {
 		if IsDiscardVar(name) {
@@ -2298,6 +2625,9 @@ func (b *BlockWalker) flushUnused() {
 			if _, ok := visitedMap[n]; ok {
 				continue
 			}
+			if _, ok := b.paramVars[n]; ok {
+				continue
+			}
 
 			visitedMap[n] = struct{}{}
 			b.r.Report(n, LevelUnused, "unused", `Unused variable %s (use $_ to ignore this inspection)`, name)
@@ -2371,6 +2701,18 @@ var fallthroughMarkerRegex = func() *regexp.Regexp {
 	return regexp.MustCompile(pattern)
 }()
 
+// reportCaseBreak reports a case c that falls through to the nextCase.
+// Suggested fix makes the fallthrough explicit, so the behavior is not changed.
+func (b *BlockWalker) reportCaseBreak(c, nextCase node.Node) {
+	var indent []byte
+	if line := nextCase.GetPosition().StartLine; line >= 1 && line <= len(b.r.Lines) {
+		ln := b.r.Lines[line-1]
+		indent = ln[:len(ln)-len(bytes.TrimLeft(ln, " \t"))]
+	}
+	fix := []TextEdit{InsertBefore(nextCase, "// fallthrough\n"+string(indent))}
+	b.r.ReportWithFix(c, LevelInformation, "caseBreak", fix, "Add break or '// fallthrough' to the end of the case")
+}
+
 func (b *BlockWalker) caseHasFallthroughComment(n node.Node) bool {
 	ffs := n.GetFreeFloating()
 	if ffs == nil {
@@ -21,7 +21,12 @@ import (
 // Version log:
 //     27 - added Static field to meta.FuncInfo
 //     28 - array type parsed as mixed[]
-const cacheVersion = 28
+//     29 - added IsVariadic and TypDeclared fields to meta.FuncParam
+//     30 - added Variadic field to meta.FuncInfo
+//     31 - added Abstract field to meta.FuncInfo, Abstract and IsInterface fields to meta.ClassInfo
+//     32 - added Taint field to meta.FuncInfo and taint analysis config version to the header
+//     33 - added PrintfLike and PrintfFormat fields to meta.PhpDocInfo
+const cacheVersion = 33
 
 var (
 	errWrongVersion = errors.New("Wrong cache version")
@@ -198,6 +203,15 @@ func writeMetaCacheHeader(wr *bufio.Writer, root *RootWalker) error {
 		return err
 	}
 
+	// Function taint summaries depend on the taint analysis config.
+	taintVer := taintConfigVersion()
+	if err := wr.WriteByte(byte(len(taintVer))); err != nil {
+		return err
+	}
+	if _, err := wr.WriteString(taintVer); err != nil {
+		return err
+	}
+
 	for i := range root.custom {
 		cacher := metaCachers[i]
 		if cacher == nil {
@@ -243,6 +257,17 @@ func readMetaCacheHeader(rd *bufio.Reader) error {
 	}
 
 	var versionBuf [256]byte
+
+	taintVerLen, err := rd.ReadByte()
+	if err != nil {
+		return err
+	}
+	if _, err := rd.Read(versionBuf[:taintVerLen]); err != nil {
+		return err
+	}
+	if string(versionBuf[:taintVerLen]) != taintConfigVersion() {
+		return errWrongVersion
+	}
 	for _, cacher := range metaCachers {
 		if cacher == nil {
 			continue
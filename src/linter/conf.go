@@ -34,6 +34,28 @@ var (
 
 	ExcludeRegex *regexp.Regexp
 
+	// UnusedSymbols enables collecting of declared and referenced symbols
+	// of the analyzed files that is required for UnusedSymbolReports.
+	UnusedSymbols bool
+
+	// UnusedSymbolsAllowRegex matches names of symbols that are used implicitly
+	// (controllers, hooks, etc) and must not be reported as unused.
+	UnusedSymbolsAllowRegex *regexp.Regexp
+
+	// TaintAnalysis enables taint tracking and function taint summaries
+	// that are required for the taint check, see taint.go.
+	TaintAnalysis bool
+
+	// SnippetContextLines and SnippetMaxLines control source code snippets
+	// that are saved in reports, see Report.Snippet. Snippets are only
+	// saved if SnippetMaxLines is positive, as they take memory.
+	SnippetContextLines int
+	SnippetMaxLines     int
+
+	// SeverityOverrides maps check names to levels that are used instead
+	// of the levels passed to the Report calls for these checks.
+	SeverityOverrides map[string]int
+
 	// actually time.Duration
 	initParseTime int64
 	initWalkTime  int64
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 
 	"github.com/Levsha-cc/noverify/src/meta"
 	"github.com/Levsha-cc/noverify/src/vscode"
@@ -117,6 +118,12 @@ func (ctx *RootContext) Report(n node.Node, level int, checkName, msg string, ar
 	ctx.w.Report(n, level, checkName, msg, args...)
 }
 
+// ReportWithFix is like Report, but also attaches a fix for the reported issue.
+// Use ReplaceNode and friends to create the text edits.
+func (ctx *RootContext) ReportWithFix(n node.Node, level int, checkName string, fix []TextEdit, msg string, args ...interface{}) {
+	ctx.w.ReportWithFix(n, level, checkName, fix, msg, args...)
+}
+
 // Scope returns variables declared at root level.
 func (ctx *RootContext) Scope() *meta.Scope {
 	return ctx.w.Scope()
@@ -157,6 +164,12 @@ func (ctx *BlockContext) Report(n node.Node, level int, checkName, msg string, a
 	ctx.w.Report(n, level, checkName, msg, args...)
 }
 
+// ReportWithFix is like Report, but also attaches a fix for the reported issue.
+// Use ReplaceNode and friends to create the text edits.
+func (ctx *BlockContext) ReportWithFix(n node.Node, level int, checkName string, fix []TextEdit, msg string, args ...interface{}) {
+	ctx.w.r.ReportWithFix(n, level, checkName, fix, msg, args...)
+}
+
 // Scope returns variables declared in this block.
 func (ctx *BlockContext) Scope() *meta.Scope {
 	return ctx.w.Scope()
@@ -227,6 +240,28 @@ var severityNames = map[int]string{
 	LevelSyntax:      "SYNTAX ",
 }
 
+// levelsByName maps user-facing severity names to levels.
+// LevelSyntax is not included as it's reserved for the syntax errors.
+var levelsByName = map[string]int{
+	"error":   LevelError,
+	"warning": LevelWarning,
+	"info":    LevelInformation,
+	"hint":    LevelHint,
+	"unused":  LevelUnused,
+	"maybe":   LevelDoNotReject,
+}
+
+// LevelName returns level name as it's printed in reports (like "ERROR" or "MAYBE").
+func LevelName(level int) string {
+	return strings.TrimSpace(severityNames[level])
+}
+
+// ParseLevel returns a level by its name (like "error" or "maybe").
+func ParseLevel(name string) (level int, ok bool) {
+	level, ok = levelsByName[strings.ToLower(name)]
+	return level, ok
+}
+
 var (
 	customBlockLinters []BlockCheckerCreateFunc
 	customRootLinters  []RootCheckerCreateFunc
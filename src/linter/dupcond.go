@@ -0,0 +1,168 @@
+package linter
+
+import (
+	"github.com/Levsha-cc/noverify/src/solver"
+	"github.com/z7zmey/php-parser/node"
+	"github.com/z7zmey/php-parser/node/expr"
+	"github.com/z7zmey/php-parser/node/expr/assign"
+	"github.com/z7zmey/php-parser/node/expr/binary"
+	"github.com/z7zmey/php-parser/node/stmt"
+	"github.com/z7zmey/php-parser/walker"
+)
+
+// checkDuplicates reports repeated conditions, identical branches and operands
+// and self-assignments that are likely to be copy-paste mistakes.
+func (b *BlockWalker) checkDuplicates(n node.Node) {
+	switch n := n.(type) {
+	case *stmt.If:
+		b.checkDupConds(n.Cond, n.ElseIf)
+		if len(n.ElseIf) == 0 && n.Else != nil {
+			b.checkDupBranchBody(n.Stmt, n.Else.(*stmt.Else).Stmt, n.Else)
+		}
+	case *stmt.AltIf:
+		b.checkDupConds(n.Cond, n.ElseIf)
+		if len(n.ElseIf) == 0 && n.Else != nil {
+			b.checkDupBranchBody(n.Stmt, n.Else.(*stmt.AltElse).Stmt, n.Else)
+		}
+	case *stmt.Switch:
+		b.checkDupCases(n.CaseList.Cases)
+	case *stmt.AltSwitch:
+		b.checkDupCases(n.CaseList.Cases)
+	case *expr.Ternary:
+		if n.IfTrue != nil && solver.NodesEqual(n.IfTrue, n.IfFalse) {
+			b.r.Report(n, LevelWarning, "dupBranchBody", "Then and else branches of ternary operator are identical")
+		}
+	case *assign.Assign:
+		if solver.NodesEqual(n.Variable, n.Expression) && !hasSideEffects(n.Variable) {
+			b.r.Report(n, LevelWarning, "selfAssign", "Assignment of %s to itself", FmtNode(n.Variable))
+		}
+	default:
+		left, right, op := binaryOperands(n)
+		if op != "" && solver.NodesEqual(left, right) && !hasSideEffects(left) {
+			b.r.Report(n, LevelWarning, "dupSubExpr", "Identical operands on both sides of %s operator", op)
+		}
+	}
+}
+
+// checkDupConds reports elseif conditions that repeat the previous conditions of the if statement.
+func (b *BlockWalker) checkDupConds(cond node.Node, elseIfs []node.Node) {
+	conds := []node.Node{cond}
+	for _, n := range elseIfs {
+		conds = append(conds, elseIfCond(n))
+	}
+
+	for i, cond := range conds {
+		if hasSideEffects(cond) {
+			continue
+		}
+		for _, prev := range conds[:i] {
+			if solver.NodesEqual(prev, cond) {
+				b.r.Report(cond, LevelWarning, "dupCond", "Duplicated condition in if-elseif chain, the branch is never executed")
+				break
+			}
+		}
+	}
+}
+
+// checkDupCases reports switch cases with the same values.
+func (b *BlockWalker) checkDupCases(cases []node.Node) {
+	var values []node.Node
+	for _, c := range cases {
+		c, ok := c.(*stmt.Case)
+		if !ok {
+			continue
+		}
+		if hasSideEffects(c.Cond) {
+			continue
+		}
+		for _, prev := range values {
+			if solver.NodesEqual(prev, c.Cond) {
+				b.r.Report(c.Cond, LevelWarning, "dupCond", "Duplicated switch case value, the case is never executed")
+				break
+			}
+		}
+		values = append(values, c.Cond)
+	}
+}
+
+// checkDupBranchBody reports if statements with identical then and else branches.
+func (b *BlockWalker) checkDupBranchBody(then, els node.Node, elseNode node.Node) {
+	if list, ok := then.(*stmt.StmtList); ok && len(list.Stmts) == 0 {
+		return
+	}
+	if solver.NodesEqual(then, els) {
+		b.r.Report(elseNode, LevelWarning, "dupBranchBody", "Then and else branches are identical")
+	}
+}
+
+// binaryOperands returns the operands of a binary expression n if it
+// makes no sense for them to be the same, op is empty for other nodes.
+func binaryOperands(n node.Node) (left, right node.Node, op string) {
+	switch n := n.(type) {
+	case *binary.Equal:
+		return n.Left, n.Right, "=="
+	case *binary.NotEqual:
+		return n.Left, n.Right, "!="
+	case *binary.Identical:
+		return n.Left, n.Right, "==="
+	case *binary.NotIdentical:
+		return n.Left, n.Right, "!=="
+	case *binary.Smaller:
+		return n.Left, n.Right, "<"
+	case *binary.SmallerOrEqual:
+		return n.Left, n.Right, "<="
+	case *binary.Greater:
+		return n.Left, n.Right, ">"
+	case *binary.GreaterOrEqual:
+		return n.Left, n.Right, ">="
+	case *binary.Spaceship:
+		return n.Left, n.Right, "<=>"
+	case *binary.BooleanAnd:
+		return n.Left, n.Right, "&&"
+	case *binary.BooleanOr:
+		return n.Left, n.Right, "||"
+	case *binary.LogicalAnd:
+		return n.Left, n.Right, "and"
+	case *binary.LogicalOr:
+		return n.Left, n.Right, "or"
+	case *binary.LogicalXor:
+		return n.Left, n.Right, "xor"
+	case *binary.BitwiseAnd:
+		return n.Left, n.Right, "&"
+	case *binary.BitwiseOr:
+		return n.Left, n.Right, "|"
+	case *binary.BitwiseXor:
+		return n.Left, n.Right, "^"
+	case *binary.Minus:
+		return n.Left, n.Right, "-"
+	case *binary.Div:
+		return n.Left, n.Right, "/"
+	case *binary.Mod:
+		return n.Left, n.Right, "%"
+	case *binary.Coalesce:
+		return n.Left, n.Right, "??"
+	}
+	return nil, nil, ""
+}
+
+// hasSideEffects reports whether evaluation of n can change the state
+// or give different results, e.g. because of function calls.
+func hasSideEffects(n node.Node) bool {
+	res := false
+	walkNode(n, func(w walker.Walkable) bool {
+		switch w.(type) {
+		case *expr.FunctionCall, *expr.MethodCall, *expr.StaticCall, *expr.New,
+			*expr.Include, *expr.IncludeOnce, *expr.Require, *expr.RequireOnce,
+			*expr.Eval, *expr.Exit, *expr.Print, *expr.ShellExec, *expr.Yield, *expr.YieldFrom,
+			*expr.PreInc, *expr.PreDec, *expr.PostInc, *expr.PostDec:
+			res = true
+		case *assign.Assign, *assign.Reference, *assign.Plus, *assign.Minus, *assign.Mul,
+			*assign.Div, *assign.Mod, *assign.Pow, *assign.Concat,
+			*assign.BitwiseAnd, *assign.BitwiseOr, *assign.BitwiseXor,
+			*assign.ShiftLeft, *assign.ShiftRight:
+			res = true
+		}
+		return !res
+	})
+	return res
+}
@@ -0,0 +1,117 @@
+package linter
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/z7zmey/php-parser/node"
+)
+
+// TextEdit describes a single source code change.
+//
+// Positions are 0-based byte offsets inside the file contents.
+// Replaced range is [StartPos, EndPos), empty range means insertion.
+type TextEdit struct {
+	StartPos    int    `json:"start_pos"`
+	EndPos      int    `json:"end_pos"`
+	Replacement string `json:"replacement"`
+}
+
+// ReplaceNode creates an edit that replaces the node source code with text.
+func ReplaceNode(n node.Node, text string) TextEdit {
+	start, end := nodeRange(n)
+	return TextEdit{StartPos: start, EndPos: end, Replacement: text}
+}
+
+// InsertBefore creates an edit that inserts text right before the node.
+func InsertBefore(n node.Node, text string) TextEdit {
+	start, _ := nodeRange(n)
+	return TextEdit{StartPos: start, EndPos: start, Replacement: text}
+}
+
+// nodeRange returns [start, end) byte offsets of the node source code.
+func nodeRange(n node.Node) (start, end int) {
+	pos := n.GetPosition()
+	return pos.StartPos - 1, pos.EndPos
+}
+
+// nodeText returns node source code.
+func (d *RootWalker) nodeText(n node.Node) []byte {
+	start, end := nodeRange(n)
+	if start < 0 || end > len(d.fileContents) || start > end {
+		return nil
+	}
+	return d.fileContents[start:end]
+}
+
+// SelectFixes returns edits from report fixes that can be applied together.
+//
+// Reports must belong to the same file. Fix is skipped entirely if any
+// of its edits overlaps with already selected ones, so some problems
+// may require another run to be fixed. Selected edits are sorted by position.
+func SelectFixes(reports []*Report) (edits []TextEdit, fixed []*Report) {
+	var candidates []*Report
+	for _, r := range reports {
+		if len(r.fix) != 0 {
+			candidates = append(candidates, r)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return fixStart(candidates[i].fix) < fixStart(candidates[j].fix)
+	})
+
+	for _, r := range candidates {
+		conflict := false
+		for _, e := range r.fix {
+			for _, selected := range edits {
+				if editsOverlap(e, selected) {
+					conflict = true
+					break
+				}
+			}
+		}
+		if conflict {
+			continue
+		}
+		edits = append(edits, r.fix...)
+		fixed = append(fixed, r)
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].StartPos < edits[j].StartPos
+	})
+	return edits, fixed
+}
+
+// ApplyTextEdits returns contents with all edits applied.
+// Edits must be sorted and non-overlapping (see SelectFixes).
+func ApplyTextEdits(contents []byte, edits []TextEdit) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(contents))
+	last := 0
+	for _, e := range edits {
+		buf.Write(contents[last:e.StartPos])
+		buf.WriteString(e.Replacement)
+		last = e.EndPos
+	}
+	buf.Write(contents[last:])
+	return buf.Bytes()
+}
+
+func fixStart(fix []TextEdit) int {
+	start := fix[0].StartPos
+	for _, e := range fix[1:] {
+		if e.StartPos < start {
+			start = e.StartPos
+		}
+	}
+	return start
+}
+
+func editsOverlap(a, b TextEdit) bool {
+	if a.StartPos == b.StartPos {
+		// Two insertions at the same position have undefined order.
+		return true
+	}
+	return a.StartPos < b.EndPos && b.StartPos < a.EndPos
+}
@@ -0,0 +1,360 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/Levsha-cc/noverify/src/meta"
+	"github.com/Levsha-cc/noverify/src/solver"
+	"github.com/z7zmey/php-parser/node"
+	"github.com/z7zmey/php-parser/node/expr"
+	"github.com/z7zmey/php-parser/node/expr/binary"
+)
+
+// Type narrowing makes variable types in if branches more precise
+// using the conditions that are known to be true or false there:
+//
+//	if ($x instanceof Foo) { /* $x is Foo */ } else { /* $x is not Foo */ }
+//	if ($x === null) { return; } /* $x is not null */
+//
+// Only the types that can be removed for sure are removed,
+// unknown types are kept as is.
+
+// typeCheckFunc describes an is_* builtin function.
+type typeCheckFunc struct {
+	typ     string // a type that is used if none of the known types matches
+	matches func(typ string) bool
+}
+
+var typeCheckFuncs = map[string]typeCheckFunc{
+	"is_string":  {typ: "string", matches: func(typ string) bool { return typ == "string" }},
+	"is_int":     {typ: "int", matches: isIntType},
+	"is_integer": {typ: "int", matches: isIntType},
+	"is_long":    {typ: "int", matches: isIntType},
+	"is_float":   {typ: "float", matches: isFloatType},
+	"is_double":  {typ: "float", matches: isFloatType},
+	"is_bool":    {typ: "bool", matches: isBoolType},
+	"is_array":   {typ: "mixed[]", matches: isArrayType},
+	"is_null":    {typ: "null", matches: func(typ string) bool { return typ == "null" }},
+	"is_object":  {typ: "object", matches: func(typ string) bool { return typ == "object" || isClassType(typ) }},
+}
+
+func isIntType(typ string) bool   { return typ == "int" || typ == "integer" }
+func isFloatType(typ string) bool { return typ == "float" || typ == "double" }
+
+func isBoolType(typ string) bool {
+	switch typ {
+	case "bool", "boolean", "true", "false":
+		return true
+	}
+	return false
+}
+
+// narrowBranch narrows variable types in the current context for a branch
+// that is executed when all prevConds are false and cond is true.
+//
+// cond is nil for else branches.
+func (b *BlockWalker) narrowBranch(prevConds []node.Node, cond node.Node) {
+	for _, c := range prevConds {
+		b.narrowTypes(c, false)
+	}
+	if cond != nil {
+		b.narrowTypes(cond, true)
+	}
+}
+
+// narrowTypes narrows variable types in the current context
+// assuming that cond evaluates to the given value.
+func (b *BlockWalker) narrowTypes(cond node.Node, value bool) {
+	switch n := cond.(type) {
+	case *expr.BooleanNot:
+		b.narrowTypes(n.Expr, !value)
+
+	case *binary.BooleanAnd:
+		if value {
+			b.narrowTypes(n.Left, true)
+			b.narrowTypes(n.Right, true)
+		}
+	case *binary.LogicalAnd:
+		if value {
+			b.narrowTypes(n.Left, true)
+			b.narrowTypes(n.Right, true)
+		}
+	case *binary.BooleanOr:
+		if !value {
+			b.narrowTypes(n.Left, false)
+			b.narrowTypes(n.Right, false)
+		}
+	case *binary.LogicalOr:
+		if !value {
+			b.narrowTypes(n.Left, false)
+			b.narrowTypes(n.Right, false)
+		}
+
+	case *expr.InstanceOf:
+		b.narrowInstanceOf(n, value)
+
+	case *expr.FunctionCall:
+		b.narrowTypeCheck(n, value)
+
+	case *binary.Identical:
+		b.narrowNullCompare(n.Left, n.Right, value, true)
+	case *binary.NotIdentical:
+		b.narrowNullCompare(n.Left, n.Right, !value, true)
+	case *binary.Equal:
+		b.narrowNullCompare(n.Left, n.Right, value, false)
+	case *binary.NotEqual:
+		b.narrowNullCompare(n.Left, n.Right, !value, false)
+
+	case *expr.Isset:
+		if value {
+			for _, v := range n.Variables {
+				b.narrowVar(v, func(typ string) bool { return typ != "null" }, "")
+			}
+		}
+	case *expr.Empty:
+		if !value {
+			b.narrowVar(n.Expr, func(typ string) bool { return typ != "null" }, "")
+		}
+
+	case *expr.Variable, *expr.PropertyFetch, *expr.StaticPropertyFetch:
+		if value {
+			b.narrowVar(n, func(typ string) bool { return typ != "null" }, "")
+		}
+	}
+}
+
+func (b *BlockWalker) narrowInstanceOf(n *expr.InstanceOf, value bool) {
+	className, ok := solver.GetClassName(b.r.st, n.Class)
+	if !ok {
+		return
+	}
+
+	instanceOf := func(typ string) bool {
+		return isClassType(typ) && solver.InstanceOf(typ, className)
+	}
+
+	if _, ok := n.Expr.(*expr.Variable); !ok {
+		if value {
+			b.ctx.customTypes = append(b.ctx.customTypes, solver.CustomType{
+				Node: n.Expr,
+				Typ:  meta.NewTypesMap(className),
+			})
+		}
+		return
+	}
+
+	if value {
+		b.narrowVar(n.Expr, instanceOf, className)
+	} else {
+		b.narrowVar(n.Expr, func(typ string) bool { return !instanceOf(typ) }, "")
+	}
+}
+
+func (b *BlockWalker) narrowTypeCheck(n *expr.FunctionCall, value bool) {
+	if len(n.ArgumentList.Arguments) != 1 {
+		return
+	}
+	arg, ok := n.ArgumentList.Arguments[0].(*node.Argument)
+	if !ok || arg.Variadic {
+		return
+	}
+	nm := strings.ToLower(strings.TrimPrefix(meta.NameNodeToString(n.Function), `\`))
+	check, ok := typeCheckFuncs[nm]
+	if !ok {
+		return
+	}
+
+	if value {
+		b.narrowVar(arg.Expr, check.matches, check.typ)
+	} else {
+		b.narrowVar(arg.Expr, func(typ string) bool { return !check.matches(typ) }, "")
+	}
+}
+
+// narrowNullCompare handles comparisons with null. isNull tells whether
+// the compared expressions are known to be equal.
+//
+// Loose comparison with null is also true for other empty values,
+// so it can only be used to remove null type.
+func (b *BlockWalker) narrowNullCompare(left, right node.Node, isNull, strict bool) {
+	v := left
+	if isNullConst(left) {
+		v = right
+	} else if !isNullConst(right) {
+		return
+	}
+
+	switch {
+	case !isNull:
+		b.narrowVar(v, func(typ string) bool { return typ != "null" }, "")
+	case strict:
+		b.narrowVar(v, func(typ string) bool { return typ == "null" }, "null")
+	}
+}
+
+func isNullConst(n node.Node) bool {
+	c, ok := n.(*expr.ConstFetch)
+	return ok && strings.EqualFold(meta.NameNodeToString(c.Constant), "null")
+}
+
+// narrowVar keeps only the types of variable or property v that match the filter.
+//
+// If no types match, v gets the fallback type,
+// or keeps its types if fallback is empty.
+func (b *BlockWalker) narrowVar(v node.Node, filter func(typ string) bool, fallback string) {
+	var varName string
+	switch v := v.(type) {
+	case *expr.Variable:
+		id, ok := v.VarName.(*node.Identifier)
+		if !ok || !b.ctx.sc.MaybeHaveVarName(id.Value) {
+			return
+		}
+		varName = id.Value
+	case *expr.PropertyFetch, *expr.StaticPropertyFetch:
+	default:
+		return
+	}
+	typ := solver.ExprTypeLocalCustom(b.ctx.sc, b.r.st, v, b.ctx.customTypes)
+
+	// Lazy types can only be resolved after indexing,
+	// before that they are unknown and kept as is.
+	types := make(map[string]struct{}, typ.Len())
+	typ.Iterate(func(t string) {
+		if isLazyType(t) && meta.IsIndexingComplete() {
+			for resolved := range solver.ResolveTypes(b.r.st.CurrentClass, meta.NewTypesMap(t), make(map[string]struct{})) {
+				types[resolved] = struct{}{}
+			}
+			return
+		}
+		types[t] = struct{}{}
+	})
+
+	narrowed := make(map[string]struct{}, len(types))
+	for t := range types {
+		if t == "mixed" {
+			// Anything can be there, so nothing can be removed.
+			narrowed = nil
+			break
+		}
+		if isLazyType(t) || filter(t) {
+			narrowed[t] = struct{}{}
+		}
+	}
+
+	var res *meta.TypesMap
+	switch {
+	case len(narrowed) != 0:
+		res = meta.NewTypesMapFromMap(narrowed)
+	case fallback != "":
+		res = meta.NewTypesMap(fallback)
+	default:
+		return
+	}
+
+	// Properties can be changed by any call, so their narrowed
+	// types are only used in the current context.
+	if varName == "" || b.narrowExpr {
+		b.ctx.customTypes = append(b.ctx.customTypes, solver.CustomType{Node: v, Typ: res})
+		return
+	}
+	b.ctx.sc.NarrowVarName(varName, res, "narrowing", b.ctx.sc.HaveVarName(varName))
+}
+
+// withNarrowed runs action assuming that cond evaluates to the given value,
+// e.g. the right operand of && is only evaluated if the left one is true.
+//
+// The narrowed types are only used inside action, all other changes
+// made by it (like assignments) are visible after it.
+func (b *BlockWalker) withNarrowed(cond node.Node, value bool, action func()) {
+	start := len(b.ctx.customTypes)
+	b.narrowExpr = true
+	b.narrowTypes(cond, value)
+	b.narrowExpr = false
+	end := len(b.ctx.customTypes)
+
+	action()
+
+	b.ctx.customTypes = append(b.ctx.customTypes[:start], b.ctx.customTypes[end:]...)
+}
+
+// assignProperty records the type of a value assigned to property fetch v,
+// so it's used instead of the declared property type in the current context.
+func (b *BlockWalker) assignProperty(v node.Node, typ *meta.TypesMap) {
+	b.ctx.customTypes = append(b.ctx.customTypes, solver.CustomType{Node: v, Typ: typ})
+}
+
+// mergeBranchProperties makes the property types that were narrowed
+// or assigned in branches visible after the statement that has them.
+//
+// contexts must represent every way to get past the statement,
+// they are copies of the current context.
+func (b *BlockWalker) mergeBranchProperties(contexts []*blockContext) {
+	var live []*blockContext
+	for _, ctx := range contexts {
+		if ctx.exitFlags == 0 {
+			live = append(live, ctx)
+		}
+	}
+	if len(live) == 0 {
+		return
+	}
+
+	// Custom types that were added by the branches.
+	start := len(b.ctx.customTypes)
+	var props []node.Node
+	for _, ctx := range live {
+		for _, c := range ctx.customTypes[start:] {
+			switch c.Node.(type) {
+			case *expr.PropertyFetch, *expr.StaticPropertyFetch:
+				if !containsNode(props, c.Node) {
+					props = append(props, c.Node)
+				}
+			}
+		}
+	}
+
+	for _, n := range props {
+		var typ *meta.TypesMap
+		for _, ctx := range live {
+			typ = typ.Append(solver.ExprTypeLocalCustom(ctx.sc, b.r.st, n, ctx.customTypes))
+		}
+		b.ctx.customTypes = append(b.ctx.customTypes, solver.CustomType{Node: n, Typ: typ})
+	}
+}
+
+func containsNode(nodes []node.Node, n node.Node) bool {
+	for _, x := range nodes {
+		if solver.NodesEqual(x, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// exprTypeNarrowed returns the type of n in the current context.
+// Ternary operator branches are narrowed by its condition,
+// so $x !== null ? $x : new Foo() is not null.
+func (b *BlockWalker) exprTypeNarrowed(n node.Node) *meta.TypesMap {
+	t, ok := n.(*expr.Ternary)
+	if !ok {
+		return solver.ExprTypeLocalCustom(b.ctx.sc, b.r.st, n, b.ctx.customTypes)
+	}
+
+	ifTrue := t.IfTrue
+	if ifTrue == nil {
+		// $x ?: $y
+		ifTrue = t.Condition
+	}
+	var typ *meta.TypesMap
+	b.withNarrowed(t.Condition, true, func() {
+		typ = b.exprTypeNarrowed(ifTrue)
+	})
+	b.withNarrowed(t.Condition, false, func() {
+		typ = typ.Append(b.exprTypeNarrowed(t.IfFalse))
+	})
+	return typ
+}
+
+func isLazyType(typ string) bool {
+	return typ != "" && typ[0] < meta.WMax
+}
@@ -0,0 +1,72 @@
+package linter
+
+import (
+	"github.com/Levsha-cc/noverify/src/meta"
+	"github.com/Levsha-cc/noverify/src/solver"
+	"github.com/z7zmey/php-parser/node"
+	"github.com/z7zmey/php-parser/node/expr"
+)
+
+// isNullable reports whether typ contains null along with other types.
+//
+// Values that are always null are reported by other checks,
+// e.g. a call of undefined method.
+func isNullable(typ *meta.TypesMap) bool {
+	if typ.Len() < 2 {
+		return false
+	}
+	nullable := false
+	typ.Iterate(func(t string) {
+		if t == "null" {
+			nullable = true
+		}
+	})
+	return nullable
+}
+
+// markNullSafe marks the fetches of n that don't fail on null values,
+// like the ones inside isset() or on the left side of ??.
+func (b *BlockWalker) markNullSafe(n node.Node) {
+	if b.nullSafe == nil {
+		b.nullSafe = make(map[node.Node]struct{})
+	}
+	for {
+		switch f := n.(type) {
+		case *expr.PropertyFetch:
+			b.nullSafe[f] = struct{}{}
+			n = f.Variable
+		case *expr.ArrayDimFetch:
+			b.nullSafe[f] = struct{}{}
+			n = f.Variable
+		default:
+			return
+		}
+	}
+}
+
+func (b *BlockWalker) isNullSafe(n node.Node) bool {
+	_, ok := b.nullSafe[n]
+	return ok
+}
+
+func (b *BlockWalker) checkNullableMethodCall(e *expr.MethodCall, methodName string, typ *meta.TypesMap) {
+	if isNullable(typ) {
+		b.r.Report(e.Method, LevelWarning, "nullable", "Call to method %s() on possibly null value of type %s", methodName, typ)
+	}
+}
+
+func (b *BlockWalker) checkNullablePropertyFetch(e *expr.PropertyFetch, propName string, typ *meta.TypesMap) {
+	if !b.isNullSafe(e) && isNullable(typ) {
+		b.r.Report(e.Property, LevelWarning, "nullable", "Fetch of property %s on possibly null value of type %s", propName, typ)
+	}
+}
+
+func (b *BlockWalker) checkNullableDimFetch(e *expr.ArrayDimFetch) {
+	if !meta.IsIndexingComplete() || b.isNullSafe(e) {
+		return
+	}
+	typ := solver.ExprTypeCustom(b.ctx.sc, b.r.st, e.Variable, b.ctx.customTypes)
+	if isNullable(typ) {
+		b.r.Report(e.Variable, LevelWarning, "nullable", "Array access on possibly null value of type %s", typ)
+	}
+}
@@ -0,0 +1,137 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/Levsha-cc/noverify/src/meta"
+	"github.com/Levsha-cc/noverify/src/solver"
+	"github.com/z7zmey/php-parser/node"
+)
+
+// checkMethodOverride reports incompatibilities between the method
+// and the parent class or interface method it overrides.
+func (d *RootWalker) checkMethodOverride(nameNode node.Node, nm string, fn meta.FuncInfo) {
+	if !meta.IsIndexingComplete() || d.st.IsTrait {
+		return
+	}
+	class, ok := meta.Info.GetClass(d.st.CurrentClass)
+	if !ok {
+		return
+	}
+
+	interfaces := collectInterfaces(d.st.CurrentClass)
+	if class.Parent != "" {
+		parent, parentClass, ok := solver.FindMethod(class.Parent, nm)
+		if ok && parent.AccessLevel != meta.Private {
+			d.checkOverride(nameNode, nm, fn, parentClass, parent)
+
+			// The method is compatible with the interfaces of the parent class
+			// if it's compatible with the parent method, only the interfaces
+			// added by the current class need to be checked.
+			for iface := range collectInterfaces(class.Parent) {
+				delete(interfaces, iface)
+			}
+		}
+	}
+
+	checked := make(map[string]struct{})
+	for _, iface := range sortedSet(interfaces) {
+		m, ifaceName, ok := solver.FindMethod(iface, nm)
+		if !ok {
+			continue
+		}
+		if _, ok := checked[ifaceName]; ok {
+			continue
+		}
+		checked[ifaceName] = struct{}{}
+		d.checkOverride(nameNode, nm, fn, ifaceName, m)
+	}
+}
+
+func (d *RootWalker) checkOverride(nameNode node.Node, nm string, fn meta.FuncInfo, parentClass string, parent meta.FuncInfo) {
+	// Constructors signatures are only enforced by abstract declarations.
+	if strings.EqualFold(nm, "__construct") && !parent.Abstract {
+		return
+	}
+
+	className := d.st.CurrentClass
+	parentName := parentClass + "::" + nm
+
+	if fn.AccessLevel > parent.AccessLevel {
+		d.Report(nameNode, LevelError, "override", "Access level to %s::%s must be %s (as in %s) or weaker", className, nm, parent.AccessLevel, parentName)
+	}
+
+	if parent.Static && !fn.Static {
+		d.Report(nameNode, LevelError, "override", "Cannot make static method %s non static in class %s", parentName, className)
+	} else if !parent.Static && fn.Static {
+		d.Report(nameNode, LevelError, "override", "Cannot make non static method %s static in class %s", parentName, className)
+	}
+
+	if reason := incompatibleParams(fn.Params, parent.Params, fn.MinParamsCnt, parent.MinParamsCnt); reason != "" {
+		d.Report(nameNode, LevelError, "override", "Declaration of %s::%s must be compatible with %s: %s", className, nm, parentName, reason)
+	}
+}
+
+// incompatibleParams returns a reason why a method with params can't override
+// a method with parentParams or an empty string if it can.
+func incompatibleParams(params, parentParams []meta.FuncParam, minParams, parentMinParams int) string {
+	variadic := len(params) != 0 && params[len(params)-1].IsVariadic
+	parentVariadic := len(parentParams) != 0 && parentParams[len(parentParams)-1].IsVariadic
+
+	switch {
+	case parentVariadic && !variadic:
+		return "variadic parameter is missing"
+	case !variadic && len(params) < len(parentParams):
+		return "fewer parameters"
+	case minParams > parentMinParams:
+		return "more required parameters"
+	}
+
+	for i, p := range params {
+		if i >= len(parentParams) || p.IsVariadic || parentParams[i].IsVariadic {
+			break
+		}
+		if p.IsRef && !parentParams[i].IsRef {
+			return "parameter $" + p.Name + " must not be passed by reference"
+		}
+		if !p.IsRef && parentParams[i].IsRef {
+			return "parameter $" + p.Name + " must be passed by reference"
+		}
+	}
+
+	return ""
+}
+
+// collectInterfaces returns all interfaces implemented by the class
+// and its parents, including the interfaces they extend.
+func collectInterfaces(className string) map[string]struct{} {
+	res := make(map[string]struct{})
+	visited := make(map[string]struct{})
+
+	var collect func(className string)
+	collect = func(className string) {
+		if _, ok := visited[className]; ok {
+			return
+		}
+		visited[className] = struct{}{}
+
+		class, ok := meta.Info.GetClass(className)
+		if !ok {
+			return
+		}
+		for iface := range class.Interfaces {
+			res[iface] = struct{}{}
+			collect(iface)
+		}
+		for _, iface := range class.ParentInterfaces {
+			res[iface] = struct{}{}
+			collect(iface)
+		}
+		if class.Parent != "" {
+			collect(class.Parent)
+		}
+	}
+
+	collect(className)
+	return res
+}
@@ -69,6 +69,12 @@ type ReadCallback func(ch chan FileInfo)
 // ParseContents parses specified contents (or file) and returns *RootWalker.
 // Function does not update global meta.
 func ParseContents(filename string, contents []byte, lineRanges []git.LineRange) (rootNode node.Node, w *RootWalker, err error) {
+	return parseContents(filename, contents, lineRanges, false)
+}
+
+// parseContents is like ParseContents, but when taintPass is set it only computes
+// the taint summaries: custom checkers are not run and nothing is reported.
+func parseContents(filename string, contents []byte, lineRanges []git.LineRange, taintPass bool) (rootNode node.Node, w *RootWalker, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			s := fmt.Sprintf("Panic while parsing %s: %s\n\nStack trace: %s", filename, r, dbg.Stack())
@@ -105,10 +111,10 @@ func ParseContents(filename string, contents []byte, lineRanges []git.LineRange)
 
 	bufCopy := append(make([]byte, 0, b.Len()), b.Bytes()...)
 
-	return analyzeFile(filename, bufCopy, parser, lineRanges)
+	return analyzeFile(filename, bufCopy, parser, lineRanges, taintPass)
 }
 
-func analyzeFile(filename string, contents []byte, parser *php7.Parser, lineRanges []git.LineRange) (rootNode node.Node, w *RootWalker, err error) {
+func analyzeFile(filename string, contents []byte, parser *php7.Parser, lineRanges []git.LineRange, taintPass bool) (rootNode node.Node, w *RootWalker, err error) {
 	start := time.Now()
 	rootNode = parser.GetRootNode()
 
@@ -121,10 +127,20 @@ func analyzeFile(filename string, contents []byte, parser *php7.Parser, lineRang
 		filename:   filename,
 		lineRanges: lineRanges,
 		st:         &meta.ClassParseState{},
+		taintPass:  taintPass,
 	}
 
 	w.InitFromParser(contents, parser)
-	w.InitCustom()
+	if !taintPass {
+		w.InitCustom()
+	}
+
+	if meta.IsIndexingComplete() && !taintPass {
+		w.collectSuppressions(rootNode)
+		if UnusedSymbols {
+			w.symbols = newSymbolsUsage(w.st)
+		}
+	}
 
 	rootNode.Walk(w)
 	if meta.IsIndexingComplete() {
@@ -138,6 +154,13 @@ func analyzeFile(filename string, contents []byte, parser *php7.Parser, lineRang
 		w.Report(nil, LevelError, "syntax", "Syntax error: "+e.String())
 	}
 
+	if meta.IsIndexingComplete() && !taintPass {
+		w.reportUnusedSuppressions()
+	}
+	if w.symbols != nil {
+		w.symbols.flush()
+	}
+
 	atomic.AddInt64(&initWalkTime, int64(time.Since(start)))
 
 	return rootNode, w, nil
@@ -0,0 +1,854 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/z7zmey/php-parser/node"
+	"github.com/z7zmey/php-parser/node/expr"
+	"github.com/z7zmey/php-parser/node/scalar"
+)
+
+// pcreFuncs maps preg_* functions to the index of their replacement argument, -1 if there is none.
+var pcreFuncs = map[string]int{
+	`\preg_match`:                  -1,
+	`\preg_match_all`:              -1,
+	`\preg_split`:                  -1,
+	`\preg_grep`:                   -1,
+	`\preg_replace_callback`:       -1,
+	`\preg_replace_callback_array`: -1,
+	`\preg_replace`:                1,
+	`\preg_filter`:                 1,
+}
+
+// checkRegexpCall checks constant patterns passed to preg_* functions.
+func (b *BlockWalker) checkRegexpCall(fqName string, args []node.Node) {
+	replacementIndex, ok := pcreFuncs[fqName]
+	if !ok || len(args) == 0 {
+		return
+	}
+	arg, ok := args[0].(*node.Argument)
+	if !ok || arg.Variadic {
+		return
+	}
+
+	if fqName == `\preg_replace_callback_array` {
+		// Patterns are the keys of the array.
+		for _, item := range arrayItems(arg.Expr) {
+			if s, ok := item.Key.(*scalar.String); ok {
+				b.checkRegexp(s)
+			}
+		}
+		return
+	}
+
+	switch e := arg.Expr.(type) {
+	case *scalar.String:
+		info, ok := b.checkRegexp(e)
+		if ok && replacementIndex >= 0 && replacementIndex < len(args) {
+			b.checkRegexpReplacement(info, args[replacementIndex])
+		}
+	case *expr.Array, *expr.ShortArray:
+		for _, item := range arrayItems(e) {
+			if s, ok := item.Val.(*scalar.String); ok {
+				b.checkRegexp(s)
+			}
+		}
+	}
+}
+
+// arrayItems returns the items of array literal n.
+func arrayItems(n node.Node) []*expr.ArrayItem {
+	var items []node.Node
+	switch n := n.(type) {
+	case *expr.Array:
+		items = n.Items
+	case *expr.ShortArray:
+		items = n.Items
+	}
+
+	res := make([]*expr.ArrayItem, 0, len(items))
+	for _, item := range items {
+		if item, ok := item.(*expr.ArrayItem); ok && item != nil {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// checkRegexp reports syntax errors in the pattern literal s
+// and returns the pattern info if it's valid.
+func (b *BlockWalker) checkRegexp(s *scalar.String) (info pcreInfo, ok bool) {
+	pattern, offsets, ok := interpretStringOffsets(s.Value)
+	if !ok {
+		return info, false
+	}
+	info, err := parsePCRE(pattern)
+	if err != nil {
+		b.r.reportInString(s, offsets[err.from], offsets[err.to], LevelError, "regexp", "Invalid regexp: %s", err.msg)
+		return info, false
+	}
+	return info, true
+}
+
+// checkRegexpReplacement reports the references to non-existent groups
+// in the constant replacement of preg_replace.
+func (b *BlockWalker) checkRegexpReplacement(info pcreInfo, arg node.Node) {
+	if info.branchReset {
+		// Group numbers are reused, so their count is not known.
+		return
+	}
+	a, ok := arg.(*node.Argument)
+	if !ok {
+		return
+	}
+	s, ok := a.Expr.(*scalar.String)
+	if !ok {
+		return
+	}
+	replacement, offsets, ok := interpretStringOffsets(s.Value)
+	if !ok {
+		return
+	}
+
+	// The same rules as in php_pcre.c: \n, $n and ${n} refer to the groups,
+	// n is one or two digits, refs preceded by a backslash are literal.
+	escaped := false
+	for i := 0; i < len(replacement); i++ {
+		c := replacement[i]
+		if c != '\\' && c != '$' {
+			escaped = false
+			continue
+		}
+		if escaped {
+			escaped = false
+			continue
+		}
+
+		j := i + 1
+		braced := c == '$' && j < len(replacement) && replacement[j] == '{'
+		if braced {
+			j++
+		}
+		digitsStart := j
+		for j < len(replacement) && j < digitsStart+2 && isDigit(replacement[j]) {
+			j++
+		}
+		if j == digitsStart || (braced && (j == len(replacement) || replacement[j] != '}')) {
+			escaped = c == '\\'
+			continue
+		}
+		group := 0
+		for _, d := range replacement[digitsStart:j] {
+			group = group*10 + int(d-'0')
+		}
+		if braced {
+			j++
+		}
+		if group > info.groups {
+			b.r.reportInString(s, offsets[i], offsets[j], LevelWarning, "regexp", "Replacement refers to non-existent group %d", group)
+		}
+		i = j - 1
+	}
+}
+
+// pcreInfo describes a valid regular expression.
+type pcreInfo struct {
+	groups      int  // number of capturing groups
+	branchReset bool // whether (?| groups that reuse group numbers are used
+}
+
+// pcreError describes a syntax error in a regular expression.
+type pcreError struct {
+	from, to int // [from, to) range of the pattern the error refers to
+	msg      string
+}
+
+// pcreRef is a backreference to a group by number or name.
+type pcreRef struct {
+	from, to int
+	group    int
+	name     string
+}
+
+// parsePCRE parses a PHP regular expression with delimiters and modifiers,
+// e.g. "/^\d+$/u", and returns the first syntax error it finds.
+//
+// The rules follow PCRE2 that is used by PHP 7.3+, constructs that
+// are rarely used are not validated and are accepted as is.
+func parsePCRE(pattern string) (pcreInfo, *pcreError) {
+	start := 0
+	for start < len(pattern) && strings.IndexByte(" \t\n\r\v\f", pattern[start]) != -1 {
+		start++
+	}
+	if start == len(pattern) {
+		return pcreInfo{}, &pcreError{0, len(pattern), "empty regular expression"}
+	}
+
+	delim := pattern[start]
+	if isDigit(delim) || isLetter(delim) || delim == '\\' || delim == 0 {
+		return pcreInfo{}, &pcreError{start, start + 1, "delimiter must not be alphanumeric or backslash"}
+	}
+	endDelim := delim
+	if i := strings.IndexByte("([{<", delim); i != -1 {
+		endDelim = ")]}>"[i]
+	}
+
+	end := -1
+	depth := 0
+	for i := start + 1; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\':
+			i++
+		case c == endDelim && depth == 0:
+			end = i
+		case c == endDelim:
+			depth--
+		case c == delim:
+			depth++
+		}
+		if end != -1 {
+			break
+		}
+	}
+	if end == -1 {
+		return pcreInfo{}, &pcreError{start, start + 1, fmt.Sprintf("no ending delimiter '%c' found", endDelim)}
+	}
+
+	p := pcreParser{s: pattern, pos: start + 1, end: end}
+	for i := end + 1; i < len(pattern); i++ {
+		switch pattern[i] {
+		case 'x':
+			p.extended = true
+		case 'J':
+			p.dupNames = true
+		case 'i', 'm', 's', 'A', 'D', 'S', 'U', 'X', 'u', 'n', ' ', '\n', '\r':
+		default:
+			return pcreInfo{}, &pcreError{i, i + 1, fmt.Sprintf("unknown modifier '%c'", pattern[i])}
+		}
+	}
+
+	if err := p.parse(); err != nil {
+		return pcreInfo{}, err
+	}
+	return p.info, nil
+}
+
+type pcreParser struct {
+	s   string // the whole pattern with delimiters
+	pos int    // current position in s
+	end int    // position of the ending delimiter
+
+	extended bool // whether whitespace and # comments are ignored
+	dupNames bool // whether duplicate group names are allowed
+
+	info  pcreInfo
+	refs  []pcreRef
+	dups  []pcreRef // duplicate group names
+	names map[string]bool
+
+	groups []int // positions of the open groups
+
+	canRepeat  bool // whether the previous item can be quantified
+	quantified bool // whether the previous item is a quantifier
+	suffixed   bool // whether the previous quantifier has lazy or possessive suffix
+}
+
+func (p *pcreParser) errorf(from, to int, format string, args ...interface{}) *pcreError {
+	return &pcreError{from: from, to: to, msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *pcreParser) parse() *pcreError {
+	for p.pos < p.end {
+		c := p.s[p.pos]
+
+		if p.extended && strings.IndexByte(" \t\n\r\v\f", c) != -1 {
+			p.pos++
+			continue
+		}
+		if p.extended && c == '#' {
+			for p.pos < p.end && p.s[p.pos] != '\n' {
+				p.pos++
+			}
+			continue
+		}
+
+		if c == '*' || c == '+' || c == '?' || (c == '{' && p.isQuantifier()) {
+			if err := p.parseQuantifier(); err != nil {
+				return err
+			}
+			continue
+		}
+		p.quantified = false
+
+		var err *pcreError
+		switch c {
+		case '\\':
+			err = p.parseEscape()
+		case '[':
+			err = p.parseClass()
+			p.canRepeat = true
+		case '(':
+			err = p.parseGroup()
+		case ')':
+			if len(p.groups) == 0 {
+				return p.errorf(p.pos, p.pos+1, "unmatched closing parenthesis")
+			}
+			p.groups = p.groups[:len(p.groups)-1]
+			p.pos++
+			p.canRepeat = true
+		case '|':
+			p.pos++
+			p.canRepeat = false
+		default:
+			p.pos++
+			p.canRepeat = true
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(p.groups) != 0 {
+		open := p.groups[len(p.groups)-1]
+		return p.errorf(open, open+1, "missing closing parenthesis")
+	}
+
+	if !p.dupNames && len(p.dups) != 0 {
+		return p.errorf(p.dups[0].from, p.dups[0].to, "two named subpatterns have the same name %q", p.dups[0].name)
+	}
+	for _, ref := range p.refs {
+		switch {
+		case ref.name != "" && !p.names[ref.name]:
+			return p.errorf(ref.from, ref.to, "reference to non-existent subpattern %q", ref.name)
+		case ref.name == "" && ref.group > p.info.groups && !p.info.branchReset:
+			return p.errorf(ref.from, ref.to, "reference to non-existent subpattern %d", ref.group)
+		}
+	}
+
+	return nil
+}
+
+// isQuantifier reports whether { at the current position starts {n}, {n,} or {n,m} quantifier.
+func (p *pcreParser) isQuantifier() bool {
+	i := skipDigits(p.s[:p.end], p.pos+1)
+	if i == p.pos+1 {
+		return false
+	}
+	if i < p.end && p.s[i] == ',' {
+		i = skipDigits(p.s[:p.end], i+1)
+	}
+	return i < p.end && p.s[i] == '}'
+}
+
+func (p *pcreParser) parseQuantifier() *pcreError {
+	start := p.pos
+	c := p.s[p.pos]
+
+	if p.quantified && !p.suffixed && (c == '?' || c == '+') {
+		// Lazy or possessive quantifier.
+		p.suffixed = true
+		p.pos++
+		return nil
+	}
+	if !p.canRepeat && !(p.quantified && c == '{') {
+		return p.errorf(start, start+1, "quantifier does not follow a repeatable item")
+	}
+
+	p.pos++
+	if c == '{' {
+		end := strings.IndexByte(p.s[p.pos:p.end], '}') + p.pos
+		min, max, hasMax := p.s[p.pos:end], "", false
+		if comma := strings.IndexByte(min, ','); comma != -1 {
+			min, max = min[:comma], min[comma+1:]
+			hasMax = max != ""
+		}
+		if hasMax && (len(min) > len(max) || (len(min) == len(max) && min > max)) {
+			return p.errorf(start, end+1, "numbers out of order in {} quantifier")
+		}
+		p.pos = end + 1
+	}
+
+	p.canRepeat = false
+	p.quantified = true
+	p.suffixed = false
+	return nil
+}
+
+func (p *pcreParser) parseGroup() *pcreError {
+	start := p.pos
+	canRepeat := p.canRepeat
+	p.pos++
+	p.canRepeat = false
+
+	if p.pos < p.end && p.s[p.pos] == '*' {
+		// Verbs like (*UTF8) and (*SKIP).
+		end := strings.IndexByte(p.s[p.pos:p.end], ')')
+		if end == -1 {
+			return p.errorf(start, p.pos+1, "(*VERB) not terminated")
+		}
+		p.pos += end + 1
+		return nil
+	}
+
+	if p.pos >= p.end || p.s[p.pos] != '?' {
+		p.info.groups++
+		p.groups = append(p.groups, start)
+		return nil
+	}
+
+	p.pos++
+	if p.pos >= p.end {
+		return p.errorf(start, p.pos, "unrecognized character after (? or (?-")
+	}
+
+	switch c := p.s[p.pos]; {
+	case c == ':' || c == '=' || c == '!' || c == '>':
+		p.pos++
+		p.groups = append(p.groups, start)
+
+	case c == '|':
+		p.pos++
+		p.info.branchReset = true
+		p.dupNames = true
+		p.groups = append(p.groups, start)
+
+	case c == '<' && p.pos+1 < p.end && (p.s[p.pos+1] == '=' || p.s[p.pos+1] == '!'):
+		// Lookbehind assertion.
+		p.pos += 2
+		p.groups = append(p.groups, start)
+
+	case c == '<' || c == '\'':
+		return p.parseNamedGroup(start, map[byte]byte{'<': '>', '\'': '\''}[c])
+
+	case c == 'P':
+		p.pos++
+		if p.pos >= p.end {
+			return p.errorf(start, p.pos, "unrecognized character after (?P")
+		}
+		switch p.s[p.pos] {
+		case '<':
+			return p.parseNamedGroup(start, '>')
+		case '=':
+			// Named backreference (?P=name).
+			p.pos++
+			return p.parseRef(start, ')', true)
+		case '>':
+			// Named subroutine call (?P>name).
+			p.pos++
+			return p.parseRef(start, ')', false)
+		}
+		return p.errorf(start, p.pos+1, "unrecognized character after (?P")
+
+	case c == '&':
+		p.pos++
+		return p.parseRef(start, ')', false)
+
+	case c == '#':
+		end := strings.IndexByte(p.s[p.pos:p.end], ')')
+		if end == -1 {
+			return p.errorf(start, p.pos+1, "missing ) after (?# comment")
+		}
+		p.pos += end + 1
+		p.canRepeat = canRepeat // quantifiers after comments apply to the previous item
+
+	case c == 'R' || isDigit(c) || ((c == '+' || c == '-') && p.pos+1 < p.end && isDigit(p.s[p.pos+1])):
+		// Recursion and subroutine calls by number.
+		end := strings.IndexByte(p.s[p.pos:p.end], ')')
+		if end == -1 {
+			return p.errorf(start, p.pos+1, "missing closing parenthesis")
+		}
+		p.pos += end + 1
+		p.canRepeat = true
+
+	case c == '(':
+		// Conditional group, the condition is either an assertion
+		// that is parsed as a group or a reference to a group.
+		p.groups = append(p.groups, start)
+		if p.pos+1 < p.end && p.s[p.pos+1] == '?' {
+			return nil
+		}
+		end := strings.IndexByte(p.s[p.pos:p.end], ')')
+		if end == -1 {
+			return p.errorf(p.pos, p.pos+1, "malformed number or name after (?(")
+		}
+		p.pos += end + 1
+
+	default:
+		return p.parseOptions(start)
+	}
+
+	return nil
+}
+
+// parseOptions parses inline options like (?i) and (?x-s:...).
+func (p *pcreParser) parseOptions(start int) *pcreError {
+	enable := true
+	for ; p.pos < p.end; p.pos++ {
+		switch c := p.s[p.pos]; c {
+		case 'i', 'm', 'n', 's', 'U', '^':
+		case 'x':
+			p.extended = enable
+		case 'J':
+			p.dupNames = p.dupNames || enable
+		case '-':
+			enable = false
+		case ':':
+			p.pos++
+			p.groups = append(p.groups, start)
+			return nil
+		case ')':
+			p.pos++
+			return nil
+		default:
+			return p.errorf(start, p.pos+1, "unrecognized character after (? or (?-")
+		}
+	}
+	return p.errorf(start, start+1, "missing closing parenthesis")
+}
+
+func (p *pcreParser) parseNamedGroup(start int, terminator byte) *pcreError {
+	p.pos++
+	nameStart := p.pos
+	name, err := p.parseName(start, terminator)
+	if err != nil {
+		return err
+	}
+
+	if p.names == nil {
+		p.names = make(map[string]bool)
+	}
+	if p.names[name] {
+		p.dups = append(p.dups, pcreRef{from: nameStart, to: nameStart + len(name), name: name})
+	}
+	p.names[name] = true
+	p.info.groups++
+	p.groups = append(p.groups, start)
+	return nil
+}
+
+// parseName parses a group name followed by terminator.
+func (p *pcreParser) parseName(start int, terminator byte) (string, *pcreError) {
+	nameStart := p.pos
+	for p.pos < p.end && (isLetter(p.s[p.pos]) || isDigit(p.s[p.pos]) || p.s[p.pos] == '_') {
+		p.pos++
+	}
+	name := p.s[nameStart:p.pos]
+	switch {
+	case name == "":
+		return "", p.errorf(start, p.pos+1, "subpattern name expected")
+	case isDigit(name[0]):
+		return "", p.errorf(nameStart, p.pos, "subpattern name must start with a non-digit")
+	case len(name) > 32:
+		return "", p.errorf(nameStart, p.pos, "subpattern name is too long (maximum 32 code units)")
+	case p.pos >= p.end || p.s[p.pos] != terminator:
+		return "", p.errorf(start, p.pos, "syntax error in subpattern name (missing terminator?)")
+	}
+	p.pos++
+	return name, nil
+}
+
+// parseRef parses a reference to a group by name or number followed by terminator.
+// Only backreferences are checked, the groups of subroutine calls can be recursive.
+func (p *pcreParser) parseRef(start int, terminator byte, backref bool) *pcreError {
+	p.canRepeat = true
+
+	if p.pos < p.end && (isDigit(p.s[p.pos]) || p.s[p.pos] == '-' || p.s[p.pos] == '+') {
+		// Absolute or relative group number.
+		numStart := p.pos
+		p.pos = skipDigits(p.s[:p.end], p.pos+1)
+		if p.pos >= p.end || p.s[p.pos] != terminator {
+			return p.errorf(start, p.pos, "syntax error in subpattern number (missing terminator?)")
+		}
+		p.pos++
+		if backref && isDigit(p.s[numStart]) {
+			return p.addRef(start, p.pos, p.s[numStart:p.pos-1])
+		}
+		return nil
+	}
+
+	name, err := p.parseName(start, terminator)
+	if err != nil {
+		return err
+	}
+	if backref {
+		p.refs = append(p.refs, pcreRef{from: start, to: p.pos, name: name})
+	}
+	return nil
+}
+
+func (p *pcreParser) addRef(from, to int, num string) *pcreError {
+	group := 0
+	for _, d := range num {
+		group = group*10 + int(d-'0')
+	}
+	if group == 0 {
+		return p.errorf(from, to, "a numbered reference must not be zero")
+	}
+	p.refs = append(p.refs, pcreRef{from: from, to: to, group: group})
+	return nil
+}
+
+// pcreEscapes are the letters that can follow a backslash.
+const pcreEscapes = "aAbBcCdDeEfgGhHkKnNopPQrRsStvVwWxXzZ"
+
+func (p *pcreParser) parseEscape() *pcreError {
+	start := p.pos
+	p.pos++
+	p.canRepeat = true
+	if p.pos >= p.end {
+		return p.errorf(start, p.pos, `\ at end of pattern`)
+	}
+
+	c := p.s[p.pos]
+	p.pos++
+	switch {
+	case c >= '1' && c <= '9':
+		// Single digit is always a backreference,
+		// larger numbers can be octal escapes.
+		if p.pos < p.end && isDigit(p.s[p.pos]) {
+			p.pos = skipDigits(p.s[:p.end], p.pos)
+			return nil
+		}
+		return p.addRef(start, p.pos, p.s[start+1:p.pos])
+
+	case c == 'g':
+		return p.parseGRef(start)
+
+	case c == 'k':
+		if p.pos >= p.end || strings.IndexByte("<'{", p.s[p.pos]) == -1 {
+			return p.errorf(start, p.pos, `\k is not followed by a braced, angle-bracketed, or quoted name`)
+		}
+		terminator := map[byte]byte{'<': '>', '\'': '\'', '{': '}'}[p.s[p.pos]]
+		p.pos++
+		name, err := p.parseName(start, terminator)
+		if err != nil {
+			return err
+		}
+		p.refs = append(p.refs, pcreRef{from: start, to: p.pos, name: name})
+
+	case c == 'Q':
+		end := strings.Index(p.s[p.pos:p.end], `\E`)
+		if end == -1 {
+			p.pos = p.end
+		} else {
+			p.pos += end + 2
+		}
+
+	case c == 'x' || c == 'o' || c == 'p' || c == 'P' || c == 'N':
+		if p.pos < p.end && p.s[p.pos] == '{' {
+			end := strings.IndexByte(p.s[p.pos:p.end], '}')
+			if end == -1 {
+				return p.errorf(start, p.pos+1, `missing } after \%c{`, c)
+			}
+			p.pos += end + 1
+		}
+
+	case c == 'c':
+		if p.pos >= p.end {
+			return p.errorf(start, p.pos, `\c at end of pattern`)
+		}
+		p.pos++
+
+	case c == 'L' || c == 'l' || c == 'U' || c == 'u':
+		return p.errorf(start, p.pos, `PCRE does not support \L, \l, \N{name}, \U, or \u`)
+
+	case isLetter(c) && strings.IndexByte(pcreEscapes, c) == -1:
+		return p.errorf(start, p.pos, `unrecognized character follows \`)
+	}
+
+	return nil
+}
+
+// parseGRef parses \g references and subroutine calls.
+func (p *pcreParser) parseGRef(start int) *pcreError {
+	if p.pos >= p.end {
+		return p.errorf(start, p.pos, `\g is not followed by a braced, angle-bracketed, or quoted name/number or by a plain number`)
+	}
+	switch c := p.s[p.pos]; {
+	case isDigit(c):
+		numStart := p.pos
+		p.pos = skipDigits(p.s[:p.end], p.pos)
+		return p.addRef(start, p.pos, p.s[numStart:p.pos])
+	case c == '-' && p.pos+1 < p.end && isDigit(p.s[p.pos+1]):
+		p.pos = skipDigits(p.s[:p.end], p.pos+1)
+		return nil
+	case c == '{':
+		p.pos++
+		return p.parseRef(start, '}', true)
+	case c == '<':
+		p.pos++
+		return p.parseRef(start, '>', false)
+	case c == '\'':
+		p.pos++
+		return p.parseRef(start, '\'', false)
+	}
+	return p.errorf(start, p.pos+1, `\g is not followed by a braced, angle-bracketed, or quoted name/number or by a plain number`)
+}
+
+// pcrePosixClasses are the names of [:name:] classes.
+var pcrePosixClasses = map[string]bool{
+	"alnum": true, "alpha": true, "ascii": true, "blank": true,
+	"cntrl": true, "digit": true, "graph": true, "lower": true,
+	"print": true, "punct": true, "space": true, "upper": true,
+	"word": true, "xdigit": true,
+}
+
+func (p *pcreParser) parseClass() *pcreError {
+	start := p.pos
+	p.pos++
+	if p.pos < p.end && p.s[p.pos] == '^' {
+		p.pos++
+	}
+	if p.pos < p.end && p.s[p.pos] == ']' {
+		// ] right after [ is a part of the class.
+		p.pos++
+	}
+
+	for p.pos < p.end {
+		if p.s[p.pos] == ']' {
+			p.pos++
+			return nil
+		}
+
+		itemStart := p.pos
+		from, err := p.parseClassItem()
+		if err != nil {
+			return err
+		}
+		if p.pos+1 >= p.end || p.s[p.pos] != '-' || p.s[p.pos+1] == ']' || p.s[p.pos+1] == '[' {
+			continue
+		}
+
+		// Range.
+		p.pos++
+		to, err := p.parseClassItem()
+		if err != nil {
+			return err
+		}
+		switch {
+		case from == -1 || to == -1:
+			return p.errorf(itemStart, p.pos, "invalid range in character class")
+		case from > to:
+			return p.errorf(itemStart, p.pos, "range out of order in character class")
+		}
+	}
+
+	return p.errorf(start, start+1, "missing terminating ] for character class")
+}
+
+// parseClassItem parses a single character or a class like \d or [:alpha:]
+// inside a character class and returns the character code, -1 for classes.
+func (p *pcreParser) parseClassItem() (rune, *pcreError) {
+	start := p.pos
+	c := p.s[p.pos]
+
+	if c == '[' && p.pos+1 < p.end && strings.IndexByte(":.=", p.s[p.pos+1]) != -1 {
+		kind := p.s[p.pos+1]
+		end := strings.Index(p.s[p.pos+2:p.end], string(kind)+"]")
+		if end != -1 {
+			name := strings.TrimPrefix(p.s[p.pos+2:p.pos+2+end], "^")
+			p.pos += end + 4
+			if kind != ':' {
+				return -1, p.errorf(start, p.pos, "POSIX collating elements are not supported")
+			}
+			if !pcrePosixClasses[name] {
+				return -1, p.errorf(start, p.pos, "unknown POSIX class name")
+			}
+			return -1, nil
+		}
+	}
+
+	if c != '\\' {
+		r, size := utf8.DecodeRuneInString(p.s[p.pos:p.end])
+		p.pos += size
+		return r, nil
+	}
+
+	p.pos++
+	if p.pos >= p.end {
+		return -1, p.errorf(start, p.pos, `\ at end of pattern`)
+	}
+	c = p.s[p.pos]
+	p.pos++
+
+	switch c {
+	case 'n':
+		return '\n', nil
+	case 't':
+		return '\t', nil
+	case 'r':
+		return '\r', nil
+	case 'f':
+		return '\f', nil
+	case 'e':
+		return '\x1b', nil
+	case 'a':
+		return '\a', nil
+	case 'b':
+		return '\b', nil
+	case 'x':
+		if p.pos < p.end && p.s[p.pos] == '{' {
+			end := strings.IndexByte(p.s[p.pos:p.end], '}')
+			if end == -1 {
+				return -1, p.errorf(start, p.pos+1, `missing } after \x{`)
+			}
+			r := rune(0)
+			for _, d := range []byte(p.s[p.pos+1 : p.pos+end]) {
+				r = r*16 + rune(hexDigitValue(d))
+			}
+			p.pos += end + 1
+			return r, nil
+		}
+		r := rune(0)
+		for i := 0; i < 2 && p.pos < p.end && isHexDigit(p.s[p.pos]); i++ {
+			r = r*16 + rune(hexDigitValue(p.s[p.pos]))
+			p.pos++
+		}
+		return r, nil
+	case 'd', 'D', 'w', 'W', 's', 'S', 'h', 'H', 'v', 'V', 'R', 'X', 'N':
+		return -1, nil
+	case 'p', 'P':
+		if p.pos < p.end && p.s[p.pos] == '{' {
+			end := strings.IndexByte(p.s[p.pos:p.end], '}')
+			if end == -1 {
+				return -1, p.errorf(start, p.pos+1, `missing } after \%c{`, c)
+			}
+			p.pos += end + 1
+		} else if p.pos < p.end {
+			p.pos++
+		}
+		return -1, nil
+	case 'Q', 'E':
+		// \Q...\E inside of a class is rare, so it isn't checked.
+		end := strings.Index(p.s[p.pos:p.end], `\E`)
+		if c == 'Q' && end != -1 {
+			p.pos += end + 2
+		}
+		return -1, nil
+	case 'L', 'l', 'U', 'u':
+		return -1, p.errorf(start, p.pos, `PCRE does not support \L, \l, \N{name}, \U, or \u`)
+	}
+
+	if isDigit(c) {
+		// Octal escape.
+		r := rune(c - '0')
+		for i := 0; i < 2 && p.pos < p.end && p.s[p.pos] >= '0' && p.s[p.pos] <= '7'; i++ {
+			r = r*8 + rune(p.s[p.pos]-'0')
+			p.pos++
+		}
+		return r, nil
+	}
+	if isLetter(c) && strings.IndexByte(pcreEscapes, c) == -1 {
+		return -1, p.errorf(start, p.pos, `unrecognized character follows \`)
+	}
+	if isLetter(c) {
+		return -1, nil
+	}
+	return rune(c), nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
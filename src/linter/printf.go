@@ -0,0 +1,310 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Levsha-cc/noverify/src/meta"
+	"github.com/z7zmey/php-parser/node"
+	"github.com/z7zmey/php-parser/node/expr"
+	"github.com/z7zmey/php-parser/node/scalar"
+)
+
+// printfFunc describes a function that takes a format string.
+type printfFunc struct {
+	format int  // index of the format argument
+	args   int  // index of the first formatted argument, -1 if they are passed as an array after the format
+	scanf  bool // whether the format is a scanf format
+}
+
+var printfFuncs = map[string]printfFunc{
+	`\printf`:   {format: 0, args: 1},
+	`\sprintf`:  {format: 0, args: 1},
+	`\fprintf`:  {format: 1, args: 2},
+	`\vprintf`:  {format: 0, args: -1},
+	`\vsprintf`: {format: 0, args: -1},
+	`\vfprintf`: {format: 1, args: -1},
+	`\sscanf`:   {format: 1, args: 2, scanf: true},
+	`\fscanf`:   {format: 1, args: 2, scanf: true},
+}
+
+// formatDirectives describes the arguments required by a format string.
+type formatDirectives struct {
+	sequential int // number of directives without an argument number
+	maxArgNum  int // max argument number of %n$s directives
+
+	// Numbers of the args that are formatted as text (%s and %c),
+	// other printf directives only output numbers.
+	textArgs []int
+}
+
+func (d formatDirectives) requiredArgs() int {
+	if d.maxArgNum > d.sequential {
+		return d.maxArgNum
+	}
+	return d.sequential
+}
+
+// checkFormatCall checks the format string of printf-like function call.
+//
+// Wrappers of printf functions can be marked with @printf-like phpdoc annotation
+// followed by the format param name, the formatted args must follow it.
+func (b *BlockWalker) checkFormatCall(fnName, fqName string, fn meta.FuncInfo, args []node.Node) {
+	f, ok := printfFuncs[fqName]
+	if !ok {
+		if !fn.Doc.PrintfLike {
+			return
+		}
+		f = printfFunc{format: fn.Doc.PrintfFormat, args: fn.Doc.PrintfFormat + 1}
+	}
+	if f.format >= len(args) {
+		return
+	}
+	formatArg, ok := args[f.format].(*node.Argument)
+	if !ok || formatArg.Variadic {
+		return
+	}
+	for _, arg := range args[:f.format] {
+		if arg, ok := arg.(*node.Argument); ok && arg.Variadic {
+			return
+		}
+	}
+	s, ok := formatArg.Expr.(*scalar.String)
+	if !ok {
+		return
+	}
+
+	parse := parsePrintfFormat
+	if f.scanf {
+		parse = parseScanfFormat
+	}
+	directives, err := parse(interpretString(s.Value))
+	if err != nil {
+		b.r.Report(formatArg, LevelWarning, "printf", "Invalid format string for %s: %v", fnName, err)
+		return
+	}
+	if directives.sequential != 0 && directives.maxArgNum != 0 {
+		b.r.Report(formatArg, LevelWarning, "printf", "Format string for %s mixes numbered %%n$ and sequential placeholders", fnName)
+		return
+	}
+
+	have, ok := formatArgsCount(f, args)
+	if !ok || (f.scanf && have == 0) {
+		// The number of args is unknown or
+		// scanf returns parsed values as an array.
+		return
+	}
+
+	want := directives.requiredArgs()
+	switch {
+	case have < want:
+		b.r.Report(formatArg, LevelWarning, "printf", "Too few arguments for %s: format string requires %d, %d given", fnName, want, have)
+	case have > want:
+		b.r.Report(formatArg, LevelWarning, "printf", "Too many arguments for %s: format string requires %d, %d given", fnName, want, have)
+	}
+}
+
+// formatArgsCount returns the number of formatted arguments
+// if it can be known statically.
+func formatArgsCount(f printfFunc, args []node.Node) (int, bool) {
+	if f.args >= 0 {
+		count := 0
+		for _, arg := range args[f.format+1:] {
+			if arg, ok := arg.(*node.Argument); ok && arg.Variadic {
+				return 0, false
+			}
+			count++
+		}
+		return count, true
+	}
+
+	if len(args) != f.format+2 {
+		return 0, false
+	}
+	arg, ok := args[f.format+1].(*node.Argument)
+	if !ok || arg.Variadic {
+		return 0, false
+	}
+	var items []node.Node
+	switch a := arg.Expr.(type) {
+	case *expr.Array:
+		items = a.Items
+	case *expr.ShortArray:
+		items = a.Items
+	default:
+		return 0, false
+	}
+	count := 0
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		if item, ok := item.(*expr.ArrayItem); ok && item.Key != nil {
+			// Keys can make the order of the values different.
+			return 0, false
+		}
+		count++
+	}
+	return count, true
+}
+
+// parseArgNum parses %n$ argument number at the beginning of s
+// and returns it along with the number of bytes consumed.
+func parseArgNum(s string) (argNum, n int, err error) {
+	for n < len(s) && isDigit(s[n]) {
+		argNum = argNum*10 + int(s[n]-'0')
+		n++
+	}
+	if n == 0 || n == len(s) || s[n] != '$' {
+		return 0, 0, nil
+	}
+	if argNum == 0 {
+		return 0, 0, fmt.Errorf("argument number must be greater than zero")
+	}
+	return argNum, n + 1, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func skipDigits(s string, i int) int {
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+// parsePrintfFormat parses a format string of sprintf and friends.
+func parsePrintfFormat(format string) (formatDirectives, error) {
+	var res formatDirectives
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+
+		argNum, n, err := parseArgNum(format[i:])
+		if err != nil {
+			return res, err
+		}
+		i += n
+
+		// Flags.
+	flags:
+		for i < len(format) {
+			switch format[i] {
+			case '-', '+', ' ', '0':
+				i++
+			case '\'':
+				// Custom padding character.
+				i += 2
+			default:
+				break flags
+			}
+		}
+
+		// Width and precision.
+		i = skipDigits(format, i)
+		if i < len(format) && format[i] == '.' {
+			i = skipDigits(format, i+1)
+		}
+		// Length modifier is ignored.
+		if i < len(format) && format[i] == 'l' {
+			i++
+		}
+
+		if i >= len(format) {
+			return res, fmt.Errorf("missing format specifier at end of string")
+		}
+		if !strings.ContainsRune("bcdeEfFgGhHosuxX", rune(format[i])) {
+			return res, fmt.Errorf("unknown format specifier %q", format[i])
+		}
+
+		if argNum != 0 {
+			if argNum > res.maxArgNum {
+				res.maxArgNum = argNum
+			}
+		} else {
+			res.sequential++
+			argNum = res.sequential
+		}
+		if format[i] == 's' || format[i] == 'c' {
+			res.textArgs = append(res.textArgs, argNum)
+		}
+	}
+
+	return res, nil
+}
+
+// parseScanfFormat parses a format string of sscanf and fscanf.
+func parseScanfFormat(format string) (formatDirectives, error) {
+	var res formatDirectives
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+
+		suppress := false
+		if i < len(format) && format[i] == '*' {
+			suppress = true
+			i++
+		}
+
+		argNum, n, err := parseArgNum(format[i:])
+		if err != nil {
+			return res, err
+		}
+		i += n
+
+		// Width and size modifiers.
+		i = skipDigits(format, i)
+		if i < len(format) && strings.ContainsRune("hlL", rune(format[i])) {
+			i++
+		}
+
+		if i >= len(format) {
+			return res, fmt.Errorf("missing format specifier at end of string")
+		}
+		switch c := format[i]; {
+		case c == '[':
+			// Character set, ']' right after '[' or '[^' is a part of the set.
+			i++
+			if i < len(format) && format[i] == '^' {
+				i++
+			}
+			if i < len(format) && format[i] == ']' {
+				i++
+			}
+			end := strings.IndexByte(format[i:], ']')
+			if end == -1 {
+				return res, fmt.Errorf("unmatched [ in format string")
+			}
+			i += end
+		case strings.ContainsRune("cdDieEfgosuxXn", rune(c)):
+		default:
+			return res, fmt.Errorf("unknown format specifier %q", c)
+		}
+
+		switch {
+		case suppress:
+		case argNum != 0:
+			if argNum > res.maxArgNum {
+				res.maxArgNum = argNum
+			}
+		default:
+			res.sequential++
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,186 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/z7zmey/php-parser/node"
+	"github.com/z7zmey/php-parser/node/expr"
+	"github.com/z7zmey/php-parser/node/expr/assign"
+	"github.com/z7zmey/php-parser/node/scalar"
+	"github.com/z7zmey/php-parser/node/stmt"
+	"github.com/z7zmey/php-parser/walker"
+)
+
+type memberKind int
+
+const (
+	memberMethod memberKind = iota
+	memberProperty
+	memberConstant
+)
+
+type memberKey struct {
+	kind memberKind
+	name string // lowercased for methods; static properties have "$" prefix
+}
+
+// privateMember is a declaration of a private class member.
+type privateMember struct {
+	memberKey
+	n node.Node
+}
+
+// classMembersUsage collects private members of the current class
+// and all member references inside the class body.
+//
+// References are matched by names only, without resolving
+// the object types: private members can't be referenced from
+// outside of the class anyway, so this can only cause false negatives.
+type classMembersUsage struct {
+	declared []privateMember
+	used     map[memberKey]bool
+
+	// Dynamic references like $this->$name make all members of the kind used.
+	dynamic map[memberKind]bool
+}
+
+func newClassMembersUsage() *classMembersUsage {
+	return &classMembersUsage{
+		used:    make(map[memberKey]bool),
+		dynamic: make(map[memberKind]bool),
+	}
+}
+
+func (u *classMembersUsage) declare(kind memberKind, name string, n node.Node) {
+	if kind == memberMethod {
+		name = strings.ToLower(name)
+	}
+	u.declared = append(u.declared, privateMember{memberKey: memberKey{kind: kind, name: name}, n: n})
+}
+
+func (u *classMembersUsage) use(kind memberKind, nameNode node.Node) {
+	id, ok := nameNode.(*node.Identifier)
+	if !ok {
+		u.dynamic[kind] = true
+		return
+	}
+	u.useName(kind, id.Value)
+}
+
+func (u *classMembersUsage) useName(kind memberKind, name string) {
+	if kind == memberMethod {
+		name = strings.ToLower(name)
+	}
+	u.used[memberKey{kind: kind, name: name}] = true
+}
+
+// markUsed records member references made by the node.
+func (u *classMembersUsage) markUsed(w walker.Walkable) {
+	switch n := w.(type) {
+	case *expr.MethodCall:
+		u.use(memberMethod, n.Method)
+	case *expr.StaticCall:
+		u.use(memberMethod, n.Call)
+	case *expr.PropertyFetch:
+		u.use(memberProperty, n.Property)
+	case *expr.StaticPropertyFetch:
+		if v, ok := n.Property.(*expr.Variable); ok {
+			if id, ok := v.VarName.(*node.Identifier); ok {
+				u.useName(memberProperty, "$"+id.Value)
+				break
+			}
+		}
+		u.dynamic[memberProperty] = true
+	case *expr.ClassConstFetch:
+		u.use(memberConstant, n.ConstantName)
+	case *assign.Assign:
+		// Assignment targets are not walked as expressions.
+		u.markUsed(n.Variable)
+	case *assign.Reference:
+		u.markUsed(n.Variable)
+	case *expr.Array:
+		u.markCallableArray(n.Items)
+	case *expr.ShortArray:
+		u.markCallableArray(n.Items)
+	case *scalar.String:
+		u.markCallableString(n.Value)
+	case *stmt.ClassMethod:
+		u.markParams(n.Params)
+	case *expr.Closure:
+		u.markParams(n.Params)
+	}
+}
+
+// markParams records references made by parameter default values.
+// Function bodies are walked separately.
+func (u *classMembersUsage) markParams(params []node.Node) {
+	for _, p := range params {
+		walkNode(p.(*node.Parameter).DefaultValue, func(w walker.Walkable) bool {
+			u.markUsed(w)
+			return true
+		})
+	}
+}
+
+// markCallableString handles callables like 'self::method'.
+// Class part is not checked, like for the other references.
+func (u *classMembersUsage) markCallableString(s string) {
+	s = unquote(s)
+	if pos := strings.LastIndex(s, "::"); pos > 0 {
+		u.useName(memberMethod, s[pos+len("::"):])
+	}
+}
+
+// markCallableArray handles callables like [$this, 'method'] and [self::class, 'method'].
+func (u *classMembersUsage) markCallableArray(items []node.Node) {
+	if len(items) != 2 {
+		return
+	}
+	item, ok := items[1].(*expr.ArrayItem)
+	if !ok || item == nil || item.Key != nil {
+		return
+	}
+	if s, ok := item.Val.(*scalar.String); ok {
+		u.useName(memberMethod, unquote(s.Value))
+	}
+}
+
+// reportUnusedPrivateMembers reports private members of the current class
+// that are never referenced inside it.
+func (d *RootWalker) reportUnusedPrivateMembers(u *classMembersUsage) {
+	cl := d.getClass()
+	if len(cl.Traits) != 0 {
+		// Trait methods can use private members of the class.
+		return
+	}
+
+	for nm := range cl.Methods {
+		switch strings.ToLower(nm) {
+		case "__call", "__callstatic":
+			u.dynamic[memberMethod] = true
+		case "__get", "__set", "__isset", "__unset":
+			u.dynamic[memberProperty] = true
+		}
+	}
+
+	for _, m := range u.declared {
+		if u.used[m.memberKey] || u.dynamic[m.kind] {
+			continue
+		}
+		switch m.kind {
+		case memberMethod:
+			d.Report(m.n, LevelUnused, "unusedPrivate", "Unused private method %s::%s", d.st.CurrentClass, nodeIdentifier(m.n))
+		case memberProperty:
+			d.Report(m.n, LevelUnused, "unusedPrivate", "Unused private property %s::$%s", d.st.CurrentClass, strings.TrimPrefix(m.name, "$"))
+		case memberConstant:
+			d.Report(m.n, LevelUnused, "unusedPrivate", "Unused private constant %s::%s", d.st.CurrentClass, m.name)
+		}
+	}
+}
+
+func nodeIdentifier(n node.Node) string {
+	if id, ok := n.(*node.Identifier); ok {
+		return id.Value
+	}
+	return ""
+}
@@ -30,6 +30,18 @@ func init() {
 			Comment: `Report mismatching args count inside call expressions.`,
 		},
 
+		{
+			Name:    "argType",
+			Default: true,
+			Comment: `Report call arguments that can't be passed to parameters with declared types.`,
+		},
+
+		{
+			Name:    "returnType",
+			Default: true,
+			Comment: `Report returned values that don't match the declared return type and missing returns.`,
+		},
+
 		{
 			Name:    "arrayAccess",
 			Default: true,
@@ -126,6 +138,90 @@ func init() {
 			Comment: `Report potentially unused variables.`,
 		},
 
+		{
+			Name:    "unusedParam",
+			Default: true,
+			Comment: `Report function, method and closure params that are never used. Params required by the parent method signature, params of methods overridden in subclasses and params named $_ are not reported.`,
+		},
+
+		{
+			Name:    "unusedPrivate",
+			Default: true,
+			Comment: `Report private methods, properties and constants that are never used inside their class.`,
+		},
+
+		{
+			Name:    "unimplemented",
+			Default: true,
+			Comment: `Report classes that don't implement abstract methods of their parents, interfaces and traits, and abstract methods declared in non-abstract classes.`,
+		},
+
+		{
+			Name:    "override",
+			Default: true,
+			Comment: `Report methods that override parent class or interface methods with incompatible signature or access level.`,
+		},
+
+		{
+			Name:    "newAbstract",
+			Default: true,
+			Comment: `Report instantiation of abstract classes, interfaces and traits.`,
+		},
+
+		{
+			Name:    "printf",
+			Default: true,
+			Comment: `Report invalid format strings and argument count mismatches in sprintf, sscanf and other printf-like calls, including functions marked with @printf-like.`,
+		},
+
+		{
+			Name:    "regexp",
+			Default: true,
+			Comment: `Report syntax errors and unknown modifiers in constant preg_* patterns and preg_replace replacements that refer to non-existent groups.`,
+		},
+
+		{
+			Name:    "dupCond",
+			Default: true,
+			Comment: `Report conditions of if-elseif chains and switch case values that repeat the previous ones.`,
+		},
+
+		{
+			Name:    "dupBranchBody",
+			Default: true,
+			Comment: `Report if statements and ternary operators with identical then and else branches.`,
+		},
+
+		{
+			Name:    "dupSubExpr",
+			Default: true,
+			Comment: `Report binary expressions with identical operands, like $a == $a or $x - $x.`,
+		},
+
+		{
+			Name:    "selfAssign",
+			Default: true,
+			Comment: `Report assignments of variables and properties to themselves.`,
+		},
+
+		{
+			Name:    "nullable",
+			Default: false,
+			Comment: `Report method calls, property fetches and array access on values that can be null.`,
+		},
+
+		{
+			Name:    "taint",
+			Default: false,
+			Comment: `Report user input (superglobals and -taint-sources) that reaches SQL queries, shell commands, echo, include and other -taint-sinks without passing -taint-sanitizers.`,
+		},
+
+		{
+			Name:    "unusedSymbol",
+			Default: false,
+			Comment: `Report functions, classes, class constants and public methods that are never referenced in the analyzed files. Symbols marked with @api are not reported.`,
+		},
+
 		{
 			Name:    "redundantCast",
 			Default: false,
@@ -155,6 +251,12 @@ func init() {
 			Default: true,
 			Comment: `Report old-style (PHP4) class constructors.`,
 		},
+
+		{
+			Name:    "unusedSuppression",
+			Default: true,
+			Comment: `Report suppression comments that don't suppress anything.`,
+		},
 	}
 
 	for _, info := range allChecks {
@@ -168,11 +270,24 @@ type Report struct {
 	startLn    string
 	startChar  int
 	startLine  int
+	endLine    int
 	endChar    int
 	level      int
 	msg        string
 	filename   string
 	isDisabled bool // user-defined flag that file should not be linted
+
+	fingerprint string
+	fix         []TextEdit
+
+	// Source lines around the report, see Snippet.
+	snippetLine int
+	snippet     []string
+
+	// Excerpt of multi-line reports (empty for single-line ones).
+	endLn           string
+	midLines        []string
+	midLinesOmitted int
 }
 
 // CheckName returns report associated check name.
@@ -180,56 +295,147 @@ func (r *Report) CheckName() string {
 	return r.checkName
 }
 
+// Level returns report severity level (one of the Level* constants).
+func (r *Report) Level() int {
+	return r.level
+}
+
+// Message returns report message text (without check name prefix).
+func (r *Report) Message() string {
+	return r.msg
+}
+
+// Line returns 1-based line number where reported issue starts.
+func (r *Report) Line() int {
+	return r.startLine
+}
+
+// EndLine returns 1-based line number where reported issue ends.
+func (r *Report) EndLine() int {
+	return r.endLine
+}
+
+// StartChar returns 0-based column (in bytes) where reported issue starts.
+func (r *Report) StartChar() int {
+	return r.startChar
+}
+
+// EndChar returns 0-based column (in bytes) right after the reported issue end.
+// For multi-line reports, the column is inside EndLine.
+func (r *Report) EndChar() int {
+	return r.endChar
+}
+
+// Context returns source code line where reported issue starts.
+func (r *Report) Context() string {
+	return r.startLn
+}
+
+// Fingerprint returns an identifier that stays the same while reported
+// code is not changed, even if it is moved inside the file.
+//
+// It's computed from check name, file name relative to the working directory,
+// enclosing class and function, normalized context line and an occurrence
+// index of such reports, so it's unique among the reports of a single run.
+func (r *Report) Fingerprint() string {
+	return r.fingerprint
+}
+
+// Snippet returns source lines around the report, as they were analyzed,
+// and the number of the first of them.
+//
+// There are at most SnippetMaxLines of the reported lines and SnippetContextLines
+// lines before and after them. Snippet is empty if snippets are not saved.
+func (r *Report) Snippet() (firstLine int, lines []string) {
+	return r.snippetLine, r.snippet
+}
+
+// Fix returns text edits that fix the reported problem (if any).
+func (r *Report) Fix() []TextEdit {
+	return r.fix
+}
+
 // MarshalJSON is used to write report in its JSON representation.
 //
 // Used for -output-json option.
 func (r *Report) MarshalJSON() ([]byte, error) {
 	type jsonReport struct {
-		CheckName string `json:"check_name"`
-		Severity  string `json:"severity"`
-		Context   string `json:"context"`
-		Message   string `json:"message"`
-		Filename  string `json:"filename"`
-		Line      int    `json:"line"`
-		StartChar int    `json:"start_char"`
-		EndChar   int    `json:"end_char"`
+		CheckName   string     `json:"check_name"`
+		Severity    string     `json:"severity"`
+		Context     string     `json:"context"`
+		Message     string     `json:"message"`
+		Filename    string     `json:"filename"`
+		Line        int        `json:"line"`
+		StartChar   int        `json:"start_char"`
+		EndLine     int        `json:"end_line"`
+		EndChar     int        `json:"end_char"`
+		Fingerprint string     `json:"fingerprint"`
+		Fix         []TextEdit `json:"fix,omitempty"`
 	}
 
 	b, err := json.Marshal(jsonReport{
-		CheckName: r.checkName,
-		Severity:  strings.TrimSpace(severityNames[r.level]),
-		Context:   r.startLn,
-		Message:   r.msg,
-		Filename:  r.filename,
-		Line:      r.startLine,
-		StartChar: r.startChar,
-		EndChar:   r.endChar,
+		CheckName:   r.checkName,
+		Severity:    strings.TrimSpace(severityNames[r.level]),
+		Context:     r.startLn,
+		Message:     r.msg,
+		Filename:    r.filename,
+		Line:        r.startLine,
+		StartChar:   r.startChar,
+		EndLine:     r.endLine,
+		EndChar:     r.endChar,
+		Fingerprint: r.fingerprint,
+		Fix:         r.fix,
 	})
 	return b, err
 }
 
 func (r *Report) String() string {
-	contextLn := strings.Builder{}
-	for i, ch := range r.startLn {
-		if i == r.startChar {
+	msg := r.msg
+	if r.checkName != "" {
+		msg = r.checkName + ": " + msg
+	}
+	header := fmt.Sprintf("%s %s at %s:%d", severityNames[r.level], msg, r.filename, r.startLine)
+
+	if r.endLine <= r.startLine {
+		return header + "\n" + r.startLn + "\n" + underline(r.startLn, r.startChar, r.endChar)
+	}
+
+	// Multi-line report: underline the first line from the start position
+	// and the last line up to the end position.
+	var sb strings.Builder
+	sb.WriteString(header)
+	sb.WriteString("\n" + r.startLn)
+	sb.WriteString("\n" + underline(r.startLn, r.startChar, len(r.startLn)))
+	for _, ln := range r.midLines {
+		sb.WriteString("\n" + ln)
+	}
+	if r.midLinesOmitted != 0 {
+		fmt.Fprintf(&sb, "\n... (%d more lines)", r.midLinesOmitted)
+	}
+	sb.WriteString("\n" + r.endLn)
+	indent := len(r.endLn) - len(strings.TrimLeft(r.endLn, " \t"))
+	sb.WriteString("\n" + underline(r.endLn, indent, r.endChar))
+	return sb.String()
+}
+
+// underline returns a line that marks [from, to) range of ln with '^' chars.
+func underline(ln string, from, to int) string {
+	var sb strings.Builder
+	for i, ch := range ln {
+		if i == from {
 			break
 		}
 		if ch == '\t' {
-			contextLn.WriteRune(ch)
+			sb.WriteRune(ch)
 		} else {
-			contextLn.WriteByte(' ')
+			sb.WriteByte(' ')
 		}
 	}
 
-	if r.endChar > r.startChar {
-		contextLn.WriteString(strings.Repeat("^", r.endChar-r.startChar))
-	}
-
-	msg := r.msg
-	if r.checkName != "" {
-		msg = r.checkName + ": " + msg
+	if to > from {
+		sb.WriteString(strings.Repeat("^", to-from))
 	}
-	return fmt.Sprintf("%s %s at %s:%d\n%s\n%s", severityNames[r.level], msg, r.filename, r.startLine, r.startLn, contextLn.String())
+	return sb.String()
 }
 
 // IsCritical returns whether or not we need to reject whole commit when found this kind of report.
@@ -2,8 +2,12 @@ package linter
 
 import (
 	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"math"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -38,6 +42,10 @@ type RootWalker struct {
 
 	lineRanges []git.LineRange
 
+	// taintPass is set when the file is walked only to compute
+	// taint summaries, see UpdateTaintSummaries.
+	taintPass bool
+
 	custom      []RootChecker
 	customBlock []BlockCheckerCreateFunc
 	customState map[string]interface{}
@@ -48,10 +56,22 @@ type RootWalker struct {
 	st               *meta.ClassParseState
 	currentClassNode node.Node
 
+	// private members usage inside the current class, nil if not collected
+	classMembers *classMembersUsage
+
+	// project-wide symbols usage, nil if not collected
+	symbols *symbolsUsage
+
 	disabledFlag bool // user-defined flag that file should not be linted
 
+	suppressions []*suppression // parsed noverify-ignore-next-line and @noverify-suppress comments
+
 	reports []*Report
 
+	// fingerprintCounts is used to compute occurrence index
+	// of reports with the same fingerprint source.
+	fingerprintCounts map[string]int
+
 	fileContents []byte
 
 	// state required for both language server and reports creation
@@ -182,6 +202,27 @@ func (d *RootWalker) State() map[string]interface{} {
 	return d.customState
 }
 
+// reportFingerprint computes a Report.Fingerprint value for the next report.
+func (d *RootWalker) reportFingerprint(checkName, contextLine string) string {
+	var scope string
+	if d.st != nil {
+		scope = d.st.CurrentClass
+		if d.st.CurrentFunction != "" {
+			scope += "::" + d.st.CurrentFunction
+		}
+	}
+	src := checkName + "\x00" + baselineFilename(d.filename) + "\x00" + scope + "\x00" + normalizeContextLine(contextLine)
+
+	if d.fingerprintCounts == nil {
+		d.fingerprintCounts = make(map[string]int)
+	}
+	index := d.fingerprintCounts[src]
+	d.fingerprintCounts[src]++
+
+	sum := sha1.Sum([]byte(src + "\x00" + strconv.Itoa(index)))
+	return hex.EncodeToString(sum[:])
+}
+
 // GetReports returns collected reports for this file.
 func (d *RootWalker) GetReports() []*Report {
 	return d.reports
@@ -214,11 +255,30 @@ func (d *RootWalker) EnterNode(w walker.Walkable) (res bool) {
 
 	state.EnterNode(d.st, w)
 
+	if d.classMembers != nil {
+		d.classMembers.markUsed(w)
+	}
+	if d.symbols != nil {
+		d.symbols.markUsed(w)
+	}
+
 	switch n := w.(type) {
 	case *stmt.Interface:
 		d.currentClassNode = n
+		if d.symbols != nil {
+			d.declareClassSymbol("interface", n.PhpDocComment, n.InterfaceName)
+		}
 	case *stmt.Class:
 		d.currentClassNode = n
+		if meta.IsIndexingComplete() && n.ClassName != nil {
+			d.classMembers = newClassMembersUsage()
+			if !isAbstractClass(n) {
+				d.checkUnimplementedMethods(n.ClassName)
+			}
+		}
+		if d.symbols != nil && n.ClassName != nil {
+			d.declareClassSymbol("class", n.PhpDocComment, n.ClassName)
+		}
 		cl := d.getClass()
 		if n.Implements != nil {
 			for _, tr := range n.Implements.InterfaceNames {
@@ -239,6 +299,9 @@ func (d *RootWalker) EnterNode(w walker.Walkable) (res bool) {
 
 	case *stmt.Trait:
 		d.currentClassNode = n
+		if d.symbols != nil {
+			d.declareClassSymbol("trait", n.PhpDocComment, n.TraitName)
+		}
 	case *stmt.TraitUse:
 		cl := d.getClass()
 		for _, tr := range n.Traits {
@@ -296,7 +359,13 @@ func (d *RootWalker) parseStartPos(pos *position.Position) (startLn []byte, star
 
 // Report registers a single report message about some found problem.
 func (d *RootWalker) Report(n node.Node, level int, checkName, msg string, args ...interface{}) {
-	if !meta.IsIndexingComplete() {
+	d.ReportWithFix(n, level, checkName, nil, msg, args...)
+}
+
+// ReportWithFix is like Report, but also attaches text edits that fix the reported problem.
+// Pass nil fix if problem can't be fixed automatically.
+func (d *RootWalker) ReportWithFix(n node.Node, level int, checkName string, fix []TextEdit, msg string, args ...interface{}) {
+	if !meta.IsIndexingComplete() || d.taintPass {
 		return
 	}
 	if d.autoGenerated && !CheckAutoGenerated {
@@ -326,8 +395,51 @@ func (d *RootWalker) Report(n node.Node, level int, checkName, msg string, args
 		pos = *n.GetPosition()
 	}
 
+	d.reportAt(pos, level, checkName, fix, msg, args...)
+}
+
+// reportInString is like Report, but it's bound to [start, end) bytes of the string literal s,
+// so the report points to the problem inside the literal.
+func (d *RootWalker) reportInString(s *scalar.String, start, end int, level int, checkName, msg string, args ...interface{}) {
+	if !meta.IsIndexingComplete() {
+		return
+	}
+	if d.autoGenerated && !CheckAutoGenerated {
+		return
+	}
+
+	pos := *s.GetPosition()
+	// Positions of the literal bytes are only known
+	// if the literal is written in the source as is.
+	if pos.EndPos-pos.StartPos+1 == len(s.Value) && start < end && end <= len(s.Value) {
+		pos.EndPos = pos.StartPos + end - 1
+		pos.StartPos += start
+		pos.StartLine = d.lineAt(pos.StartPos - 1)
+		pos.EndLine = d.lineAt(pos.EndPos - 1)
+	}
+
+	d.reportAt(pos, level, checkName, nil, msg, args...)
+}
+
+// lineAt returns a number of the line that contains the byte at offset.
+func (d *RootWalker) lineAt(offset int) int {
+	return sort.Search(len(d.LinesPositions), func(i int) bool {
+		return d.LinesPositions[i] > offset
+	})
+}
+
+// reportAt is like ReportWithFix, but it's bound to the source code position instead of a node.
+func (d *RootWalker) reportAt(pos position.Position, level int, checkName string, fix []TextEdit, msg string, args ...interface{}) {
+	if l, ok := SeverityOverrides[checkName]; ok {
+		level = l
+	}
+	if d.suppressed(pos.StartLine, checkName) {
+		return
+	}
+
 	var endLn []byte
 	var endChar int
+	endLine := pos.StartLine
 
 	startLn, startChar := d.parseStartPos(&pos)
 
@@ -337,6 +449,9 @@ func (d *RootWalker) Report(n node.Node, level int, checkName, msg string, args
 		if pos.EndPos > p {
 			endChar = pos.EndPos - p
 		}
+		if pos.EndLine > pos.StartLine {
+			endLine = pos.EndLine
+		}
 	} else {
 		endLn = startLn
 	}
@@ -365,20 +480,72 @@ func (d *RootWalker) Report(n node.Node, level int, checkName, msg string, args
 			d.Diagnostics = append(d.Diagnostics, diag)
 		}
 	} else {
-		d.reports = append(d.reports, &Report{
-			checkName:  checkName,
-			startLn:    string(startLn),
-			startChar:  startChar,
-			startLine:  pos.StartLine,
-			endChar:    endChar,
-			level:      level,
-			filename:   d.filename,
-			msg:        fmt.Sprintf(msg, args...),
-			isDisabled: d.disabledFlag,
-		})
+		r := &Report{
+			checkName:   checkName,
+			startLn:     string(startLn),
+			startChar:   startChar,
+			startLine:   pos.StartLine,
+			endLine:     endLine,
+			endChar:     endChar,
+			level:       level,
+			filename:    d.filename,
+			msg:         fmt.Sprintf(msg, args...),
+			isDisabled:  d.disabledFlag,
+			fingerprint: d.reportFingerprint(checkName, string(startLn)),
+			fix:         fix,
+		}
+		if SnippetMaxLines > 0 {
+			r.snippetLine, r.snippet = d.reportSnippet(pos.StartLine, endLine)
+		}
+		if endLine != pos.StartLine {
+			r.endLn = string(endLn)
+			r.midLines, r.midLinesOmitted = d.reportMidLines(pos.StartLine, endLine)
+		}
+		d.reports = append(d.reports, r)
 	}
 }
 
+// maxReportMidLines is a max number of lines between the first
+// and the last line of a multi-line report that are shown in its excerpt.
+const maxReportMidLines = 3
+
+// reportMidLines returns source lines between startLine and endLine (exclusive)
+// to be shown in the report excerpt, along with a number of lines that were omitted.
+func (d *RootWalker) reportMidLines(startLine, endLine int) (lines []string, omitted int) {
+	from, to := startLine+1, endLine-1
+	if to-from+1 > maxReportMidLines {
+		omitted = to - from + 1 - (maxReportMidLines - 1)
+		to = from + maxReportMidLines - 2
+	}
+	for ln := from; ln <= to && ln <= len(d.Lines); ln++ {
+		lines = append(lines, string(d.Lines[ln-1]))
+	}
+	return lines, omitted
+}
+
+// reportSnippet returns source lines around [startLine, endLine] range
+// to be saved in the report along with the number of the first of them.
+func (d *RootWalker) reportSnippet(startLine, endLine int) (first int, lines []string) {
+	if startLine <= 0 || startLine > len(d.Lines) {
+		return 0, nil
+	}
+	if endLine > startLine+SnippetMaxLines-1 {
+		endLine = startLine + SnippetMaxLines - 1
+	}
+	first = startLine - SnippetContextLines
+	if first < 1 {
+		first = 1
+	}
+	last := endLine + SnippetContextLines
+	if last > len(d.Lines) {
+		last = len(d.Lines)
+	}
+	for ln := first; ln <= last; ln++ {
+		lines = append(lines, strings.TrimRight(string(d.Lines[ln-1]), "\r"))
+	}
+	return first, lines
+}
+
 func (d *RootWalker) reportUndefinedVariable(s *expr.Variable, maybeHave bool) {
 	name, ok := s.VarName.(*node.Identifier)
 	if !ok {
@@ -421,10 +588,50 @@ func (d *RootWalker) handleComment(c freefloating.String) {
 	}
 }
 
-func (d *RootWalker) handleFuncStmts(params []meta.FuncParam, uses, stmts []node.Node, sc *meta.Scope) (returnTypes *meta.TypesMap, prematureExitFlags int) {
+// funcReturnType is a declared function return type.
+// Return statements inside the function body are checked against it.
+type funcReturnType struct {
+	funcName string
+	nameNode node.Node
+	typ      *meta.TypesMap // native type hint or phpdoc @return type
+	ctor     bool           // constructors can't return a value
+}
+
+// newFuncReturnType returns nil if function return type can't be checked.
+func newFuncReturnType(funcName string, nameNode node.Node, hintType, phpdocType *meta.TypesMap, ctor bool, stmts []node.Node) *funcReturnType {
+	typ := hintType
+	if typ.IsEmpty() {
+		typ = phpdocType
+	}
+	if typ.IsEmpty() && !ctor {
+		return nil
+	}
+
+	// Generators return values are not the same as function results.
+	isGenerator := funcBodyContains(stmts, func(n node.Node) bool {
+		switch n.(type) {
+		case *expr.Yield, *expr.YieldFrom:
+			return true
+		}
+		return false
+	})
+	if isGenerator {
+		return nil
+	}
+
+	return &funcReturnType{
+		funcName: funcName,
+		nameNode: nameNode,
+		typ:      typ,
+		ctor:     ctor,
+	}
+}
+
+func (d *RootWalker) handleFuncStmts(params []meta.FuncParam, uses, stmts []node.Node, sc *meta.Scope, ret *funcReturnType, unused *unusedParamsCheck) (returnTypes *meta.TypesMap, prematureExitFlags int, returnTaint meta.Taint) {
 	b := &BlockWalker{
 		ctx:          &blockContext{sc: sc},
 		r:            d,
+		funcReturn:   ret,
 		unusedVars:   make(map[string][]node.Node),
 		nonLocalVars: make(map[string]struct{}),
 	}
@@ -458,15 +665,20 @@ func (d *RootWalker) handleFuncStmts(params []meta.FuncParam, uses, stmts []node
 		}
 	}
 
-	for _, p := range params {
+	for i, p := range params {
 		if p.IsRef {
 			b.nonLocalVars[p.Name] = struct{}{}
 		}
+		if TaintAnalysis {
+			sc.SetVarTaint(p.Name, meta.TaintParam(i))
+		}
 	}
+	b.trackUnusedParams(unused)
 	for _, s := range stmts {
 		b.addStatement(s)
 		s.Walk(b)
 	}
+	b.checkMissingReturn(stmts)
 	b.flushUnused()
 
 	// we can mark function as exiting abnormally if and only if
@@ -485,7 +697,14 @@ func (d *RootWalker) handleFuncStmts(params []meta.FuncParam, uses, stmts []node
 		b.returnTypes = meta.MixedType
 	}
 
-	return b.returnTypes, prematureExitFlags
+	returnTaint = b.returnTaint
+	if TaintAnalysis && len(stmts) == 0 {
+		// Functions without bodies are usually declared in stubs,
+		// their results are assumed to depend on all params.
+		returnTaint = meta.TaintAnyParam
+	}
+
+	return b.returnTypes, prematureExitFlags, returnTaint
 }
 
 func (d *RootWalker) getElementPos(n node.Node) meta.ElementPosition {
@@ -557,6 +776,7 @@ func (d *RootWalker) getClass() meta.ClassInfo {
 
 	cl, ok := m[d.st.CurrentClass]
 	if !ok {
+		_, isInterface := d.currentClassNode.(*stmt.Interface)
 		cl = meta.ClassInfo{
 			Pos:              d.getElementPos(d.currentClassNode),
 			Parent:           d.st.CurrentParentClass,
@@ -566,6 +786,8 @@ func (d *RootWalker) getClass() meta.ClassInfo {
 			Methods:          make(meta.FunctionsMap),
 			Properties:       make(meta.PropertiesMap),
 			Constants:        make(meta.ConstantsMap),
+			Abstract:         isAbstractClass(d.currentClassNode),
+			IsInterface:      isInterface,
 		}
 
 		m[d.st.CurrentClass] = cl
@@ -611,6 +833,10 @@ func (d *RootWalker) enterPropertyList(pl *stmt.PropertyList) bool {
 			nm = "$" + nm
 		}
 
+		if accessLevel == meta.Private && d.classMembers != nil {
+			d.classMembers.declare(memberProperty, nm, p.Variable)
+		}
+
 		// TODO: handle duplicate property
 		cl.Properties[nm] = meta.PropertyInfo{
 			Pos:         d.getElementPos(p),
@@ -643,6 +869,13 @@ func (d *RootWalker) enterClassConstList(s *stmt.ClassConstList) bool {
 		nm := c.ConstantName.(*node.Identifier).Value
 		typ := solver.ExprTypeLocal(d.meta.Scope, d.st, c.Expr)
 
+		if accessLevel == meta.Private && d.classMembers != nil {
+			d.classMembers.declare(memberConstant, nm, c.ConstantName)
+		}
+		if accessLevel != meta.Private && d.symbols != nil {
+			d.declareClassConstSymbol(c, nm)
+		}
+
 		// TODO: handle duplicate constant
 		cl.Constants[nm] = meta.ConstantInfo{
 			Pos:         d.getElementPos(c),
@@ -657,13 +890,30 @@ func (d *RootWalker) enterClassConstList(s *stmt.ClassConstList) bool {
 func (d *RootWalker) checkOldStyleConstructor(meth *stmt.ClassMethod, nm string) {
 	lastDelim := strings.IndexByte(d.st.CurrentClass, '\\')
 	if strings.EqualFold(d.st.CurrentClass[lastDelim+1:], nm) {
-		_, isClass := d.currentClassNode.(*stmt.Class)
+		class, isClass := d.currentClassNode.(*stmt.Class)
 		if isClass {
-			d.Report(meth.MethodName, LevelDoNotReject, "oldStyleConstructor", "Old-style constructor usage, use __construct instead")
+			var fix []TextEdit
+			// If there is __construct, old-style constructor is not called by PHP
+			// and renaming it would produce duplicated method declaration.
+			if !classHasMethod(class, "__construct") {
+				fix = []TextEdit{ReplaceNode(meth.MethodName, "__construct")}
+			}
+			d.ReportWithFix(meth.MethodName, LevelDoNotReject, "oldStyleConstructor", fix, "Old-style constructor usage, use __construct instead")
 		}
 	}
 }
 
+// classHasMethod reports whether class declares a method with a given name.
+func classHasMethod(class *stmt.Class, name string) bool {
+	for _, s := range class.Stmts {
+		meth, ok := s.(*stmt.ClassMethod)
+		if ok && strings.EqualFold(meth.MethodName.(*node.Identifier).Value, name) {
+			return true
+		}
+	}
+	return false
+}
+
 func (d *RootWalker) enterClassMethod(meth *stmt.ClassMethod) bool {
 	nm := meth.MethodName.(*node.Identifier).Value
 
@@ -677,6 +927,14 @@ func (d *RootWalker) enterClassMethod(meth *stmt.ClassMethod) bool {
 
 	modif := d.parseMethodModifiers(meth)
 
+	// Magic methods are called implicitly.
+	if modif.accessLevel == meta.Private && d.classMembers != nil && !strings.HasPrefix(nm, "__") {
+		d.classMembers.declare(memberMethod, nm, meth.MethodName)
+	}
+	if modif.accessLevel == meta.Public && d.symbols != nil {
+		d.declareMethodSymbol(meth, nm)
+	}
+
 	sc := meta.NewScope()
 	if !modif.static {
 		sc.AddVarName("this", meta.NewTypesMap(d.st.CurrentClass).Immutable(), "instance method", true)
@@ -688,8 +946,14 @@ func (d *RootWalker) enterClassMethod(meth *stmt.ClassMethod) bool {
 		specifiedReturnType = typ
 	}
 
+	_, insideInterface := d.currentClassNode.(*stmt.Interface)
+	if modif.abstract {
+		if class, ok := d.currentClassNode.(*stmt.Class); ok && !isAbstractClass(class) {
+			d.Report(meth.MethodName, LevelError, "unimplemented", "Class %s contains abstract method %s and must be declared abstract", d.st.CurrentClass, nm)
+		}
+	}
+
 	if meth.PhpDocComment == "" && modif.accessLevel == meta.Public {
-		_, insideInterface := d.currentClassNode.(*stmt.Interface)
 		// Permit having "__call" and other magic method without comments.
 		if !insideInterface && !strings.HasPrefix(nm, "_") {
 			d.Report(meth.MethodName, LevelDoNotReject, "phpdoc", "Missing PHPDoc for %q public method", nm)
@@ -738,10 +1002,28 @@ func (d *RootWalker) enterClassMethod(meth *stmt.ClassMethod) bool {
 	}
 
 	var stmts []node.Node
-	if stmtList, ok := meth.Stmt.(*stmt.StmtList); ok {
+	stmtList, hasBody := meth.Stmt.(*stmt.StmtList)
+	if hasBody {
 		stmts = stmtList.Stmts
 	}
-	actualReturnTypes, exitFlags := d.handleFuncStmts(params, nil, stmts, sc)
+	var ret *funcReturnType
+	if hasBody {
+		ret = newFuncReturnType(d.st.CurrentClass+"::"+nm, meth.MethodName, specifiedReturnType, phpdocReturnType, strings.EqualFold(nm, "__construct"), stmts)
+	}
+	var unused *unusedParamsCheck
+	// Magic methods signatures are defined by the language,
+	// trait methods can implement the methods of the classes that use them.
+	if !d.st.IsTrait && (!strings.HasPrefix(nm, "__") || strings.EqualFold(nm, "__construct")) {
+		unused = newUnusedParamsCheck(meth.Params, stmts)
+	}
+	if unused != nil && meta.IsIndexingComplete() {
+		unused.inherited = d.inheritedParamsCount(nm)
+		// Overriding methods can use the params, so they can't be removed.
+		if modif.accessLevel != meta.Private && isOverridden(d.st.CurrentClass, nm) {
+			unused = nil
+		}
+	}
+	actualReturnTypes, exitFlags, returnTaint := d.handleFuncStmts(params, nil, stmts, sc, ret, unused)
 
 	d.addScope(meth, sc)
 
@@ -750,6 +1032,10 @@ func (d *RootWalker) enterClassMethod(meth *stmt.ClassMethod) bool {
 	if returnType.Len() == 0 {
 		returnType = meta.VoidType
 	}
+
+	// Implementations of abstract methods can read extra arguments with func_get_args().
+	variadic := !hasBody || isVariadicFunc(params, stmts)
+
 	class.Methods[nm] = meta.FuncInfo{
 		Params:       params,
 		Pos:          d.getElementPos(meth),
@@ -757,9 +1043,13 @@ func (d *RootWalker) enterClassMethod(meth *stmt.ClassMethod) bool {
 		MinParamsCnt: minParamsCnt,
 		AccessLevel:  modif.accessLevel,
 		Static:       modif.static,
+		Abstract:     modif.abstract || insideInterface,
+		Variadic:     variadic,
 		ExitFlags:    exitFlags,
+		Taint:        returnTaint,
 		Doc:          doc.info,
 	}
+	d.checkMethodOverride(meth.MethodName, nm, class.Methods[nm])
 
 	if nm == "getIterator" && meta.IsIndexingComplete() && solver.Implements(d.st.CurrentClass, `\IteratorAggregate`) {
 		implementsTraversable := false
@@ -905,7 +1195,7 @@ func (d *RootWalker) maybeAddNamespace(typStr string) string {
 		}
 
 		switch className {
-		case "bool", "boolean", "true", "false", "double", "float", "string", "int", "array", "resource", "mixed", "null", "callable", "void", "object":
+		case "bool", "boolean", "true", "false", "double", "float", "string", "int", "array", "resource", "mixed", "null", "callable", "iterable", "void", "object":
 			continue
 		case "$this":
 			// Handle `$this` as `static` alias in phpdoc context.
@@ -975,6 +1265,11 @@ func (d *RootWalker) parsePHPDoc(doc string, actualParams []node.Node) phpDocPar
 			continue
 		}
 
+		if part.Name == "printf-like" {
+			d.parsePrintfLike(&result, part, actualParams)
+			continue
+		}
+
 		if part.Name == "return" && len(part.Params) >= 1 {
 			typ, err := d.fixPHPDocType(part.Params[0])
 			if err != "" {
@@ -1045,6 +1340,35 @@ func (d *RootWalker) parsePHPDoc(doc string, actualParams []node.Node) phpDocPar
 	return result
 }
 
+// parsePrintfLike handles "@printf-like [$format]" annotation.
+// The format param defaults to the first one, the formatted args must follow it.
+func (d *RootWalker) parsePrintfLike(result *phpDocParseResult, part phpdoc.CommentPart, actualParams []node.Node) {
+	if len(part.Params) == 0 {
+		if len(actualParams) == 0 {
+			result.errs.pushLint("@printf-like function must have a format param on line %d", part.Line)
+			return
+		}
+		result.info.PrintfLike = true
+		result.info.PrintfFormat = 0
+		return
+	}
+
+	name := part.Params[0]
+	if !strings.HasPrefix(name, "$") {
+		result.errs.pushLint("@printf-like param name must start with `$` on line %d", part.Line)
+		return
+	}
+	for i, p := range actualParams {
+		v := p.(*node.Parameter).Variable.(*expr.Variable).VarName.(*node.Identifier).Value
+		if v == name[len("$"):] {
+			result.info.PrintfLike = true
+			result.info.PrintfFormat = i
+			return
+		}
+	}
+	result.errs.pushLint("@printf-like refers to unknown param %s on line %d", name, part.Line)
+}
+
 // parse type info, e.g. "string" in "someFunc() : string { ... }"
 func (d *RootWalker) parseTypeNode(n node.Node) (typ *meta.TypesMap, ok bool) {
 	if n == nil {
@@ -1084,7 +1408,9 @@ func (d *RootWalker) parseFuncArgs(params []node.Node, parTypes phpDocParamsMap,
 			if varTyp, ok := d.parseTypeNode(p.VariableType); ok {
 				typ = varTyp
 			}
-		} else if typ.IsEmpty() && p.DefaultValue != nil {
+		}
+		declared := !typ.IsEmpty()
+		if !declared && p.VariableType == nil && p.DefaultValue != nil {
 			typ = solver.ExprTypeLocal(sc, d.st, p.DefaultValue)
 		}
 
@@ -1096,9 +1422,17 @@ func (d *RootWalker) parseFuncArgs(params []node.Node, parTypes phpDocParamsMap,
 
 		sc.AddVar(v, typ, "param", true)
 
+		paramTyp := typ
+		if declared && p.DefaultValue != nil && solver.ExprTypeLocal(sc, d.st, p.DefaultValue).Is("null") {
+			// "Foo $x = null" makes the parameter implicitly nullable.
+			paramTyp = meta.NewEmptyTypesMap(typ.Len() + 1).Append(typ).AppendString("null")
+		}
+
 		par := meta.FuncParam{
-			Typ:   typ.Immutable(),
-			IsRef: p.ByRef,
+			Typ:         paramTyp.Immutable(),
+			IsRef:       p.ByRef,
+			IsVariadic:  p.Variadic,
+			TypDeclared: declared,
 		}
 
 		if id, ok := v.VarName.(*node.Identifier); ok {
@@ -1136,7 +1470,8 @@ func (d *RootWalker) enterFunction(fun *stmt.Function) bool {
 
 	params, minParamsCnt := d.parseFuncArgs(fun.Params, phpDocParamTypes, sc)
 
-	actualReturnTypes, exitFlags := d.handleFuncStmts(params, nil, fun.Stmts, sc)
+	ret := newFuncReturnType(nm, fun.FunctionName, specifiedReturnType, phpdocReturnType, false, fun.Stmts)
+	actualReturnTypes, exitFlags, returnTaint := d.handleFuncStmts(params, nil, fun.Stmts, sc, ret, newUnusedParamsCheck(fun.Params, fun.Stmts))
 	d.addScope(fun, sc)
 
 	returnType := meta.MergeTypeMaps(phpdocReturnType, actualReturnTypes, specifiedReturnType)
@@ -1148,29 +1483,96 @@ func (d *RootWalker) enterFunction(fun *stmt.Function) bool {
 		d.checkFuncParam(param.(*node.Parameter))
 	}
 
+	if d.symbols != nil {
+		d.declareSymbol(functionSymbol(nm), nm, fun.PhpDocComment, fun.FunctionName, "Unused function %s")
+	}
+
 	d.meta.Functions[nm] = meta.FuncInfo{
 		Params:       params,
 		Pos:          d.getElementPos(fun),
 		Typ:          returnType.Immutable(),
 		MinParamsCnt: minParamsCnt,
+		Variadic:     isVariadicFunc(params, fun.Stmts),
 		ExitFlags:    exitFlags,
+		Taint:        returnTaint,
 		Doc:          doc.info,
 	}
 
 	return false
 }
 
+// isVariadicFunc reports whether function accepts any number of arguments:
+// it has a variadic param or reads its arguments using func_get_args() and friends.
+func isVariadicFunc(params []meta.FuncParam, stmts []node.Node) bool {
+	if len(params) != 0 {
+		last := params[len(params)-1]
+		// PhpStorm stubs use "$_" as the last param name
+		// to describe functions with a variable number of arguments.
+		if last.IsVariadic || last.Name == "_" {
+			return true
+		}
+	}
+
+	return funcBodyContains(stmts, func(n node.Node) bool {
+		call, ok := n.(*expr.FunctionCall)
+		if !ok {
+			return false
+		}
+		switch meta.NameNodeToString(call.Function) {
+		case "func_get_args", `\func_get_args`, "func_get_arg", `\func_get_arg`, "func_num_args", `\func_num_args`:
+			return true
+		}
+		return false
+	})
+}
+
+// funcBodyContains reports whether function body has a node matching pred.
+// Nested functions and classes are not inspected.
+func funcBodyContains(stmts []node.Node, pred func(node.Node) bool) bool {
+	found := false
+	for _, s := range stmts {
+		walkNode(s, func(w walker.Walkable) bool {
+			switch n := w.(type) {
+			case *expr.Closure, *stmt.Function, *stmt.Class:
+				return false
+			case node.Node:
+				if pred(n) {
+					found = true
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
+
 func (d *RootWalker) checkFuncParam(p *node.Parameter) {
 	// TODO(quasilyte): DefaultValue can only contain constant expressions.
 	// Could run special check over them to detect the potential fatal errors.
 	walkNode(p.DefaultValue, func(w walker.Walkable) bool {
 		if n, ok := w.(*expr.Array); ok {
-			d.Report(n, LevelDoNotReject, "arraySyntax", "Use of old array syntax (use short form instead)")
+			d.ReportWithFix(n, LevelDoNotReject, "arraySyntax", d.shortArraySyntaxFix(n), "Use of old array syntax (use short form instead)")
 		}
 		return true
 	})
 }
 
+var oldArrayPrefixRegex = regexp.MustCompile(`(?i)^array\s*\(`)
+
+// shortArraySyntaxFix returns a fix that rewrites array(...) into [...].
+func (d *RootWalker) shortArraySyntaxFix(arr *expr.Array) []TextEdit {
+	src := d.nodeText(arr)
+	prefix := oldArrayPrefixRegex.Find(src)
+	if prefix == nil || !bytes.HasSuffix(src, []byte(")")) {
+		return nil
+	}
+	start, end := nodeRange(arr)
+	return []TextEdit{
+		{StartPos: start, EndPos: start + len(prefix), Replacement: "["},
+		{StartPos: end - 1, EndPos: end, Replacement: "]"},
+	}
+}
+
 func (d *RootWalker) enterFunctionCall(s *expr.FunctionCall) bool {
 	nm, ok := s.Function.(*name.Name)
 	if !ok {
@@ -1331,6 +1733,11 @@ func (d *RootWalker) LeaveNode(n walker.Walkable) {
 	case *stmt.Class, *stmt.Interface, *stmt.Trait:
 		d.getClass() // populate classes map
 
+		if d.classMembers != nil {
+			d.reportUnusedPrivateMembers(d.classMembers)
+			d.classMembers = nil
+		}
+
 		d.currentClassNode = nil
 	}
 
@@ -1,5 +1,11 @@
 package linter
 
+import (
+	"github.com/z7zmey/php-parser/node"
+	"github.com/z7zmey/php-parser/node/expr"
+	"github.com/z7zmey/php-parser/node/scalar"
+)
+
 var superGlobals = map[string]struct{}{
 	"GLOBALS":  {},
 	"_SERVER":  {},
@@ -11,3 +17,44 @@ var superGlobals = map[string]struct{}{
 	"_SESSION": {},
 	"_ENV":     {},
 }
+
+// safeServerKeys are the keys of $_SERVER that are set by the server
+// and can't contain user input.
+var safeServerKeys = map[string]struct{}{
+	"REQUEST_TIME":       {},
+	"REQUEST_TIME_FLOAT": {},
+	"REMOTE_ADDR":        {},
+	"REMOTE_PORT":        {},
+	"SERVER_ADDR":        {},
+	"SERVER_PORT":        {},
+	"DOCUMENT_ROOT":      {},
+	"argc":               {},
+}
+
+// isSafeServerVar reports whether n is a fetch of $_SERVER
+// value that can't contain user input, like $_SERVER['REQUEST_TIME'].
+func isSafeServerVar(n *expr.ArrayDimFetch) bool {
+	v, ok := n.Variable.(*expr.Variable)
+	if !ok {
+		return false
+	}
+	if id, ok := v.VarName.(*node.Identifier); !ok || id.Value != "_SERVER" {
+		return false
+	}
+	key, ok := n.Dim.(*scalar.String)
+	if !ok {
+		return false
+	}
+	_, ok = safeServerKeys[unquote(key.Value)]
+	return ok
+}
+
+// taintedSuperGlobals are the superglobals that contain user input.
+var taintedSuperGlobals = map[string]struct{}{
+	"_SERVER":  {},
+	"_GET":     {},
+	"_POST":    {},
+	"_REQUEST": {},
+	"_COOKIE":  {},
+	"_FILES":   {},
+}
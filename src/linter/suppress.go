@@ -0,0 +1,198 @@
+package linter
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/Levsha-cc/noverify/src/phpdoc"
+	"github.com/z7zmey/php-parser/freefloating"
+	"github.com/z7zmey/php-parser/node"
+	"github.com/z7zmey/php-parser/position"
+	"github.com/z7zmey/php-parser/walker"
+)
+
+const (
+	// ignoreNextLineMarker is a line comment that suppresses reports on the next line:
+	//	// noverify-ignore-next-line undefined,argCount
+	ignoreNextLineMarker = "noverify-ignore-next-line"
+
+	// suppressTag is a phpdoc tag that suppresses reports inside the documented node:
+	//	/** @noverify-suppress unused */
+	suppressTag = "noverify-suppress"
+)
+
+// suppression is a parsed suppression comment.
+type suppression struct {
+	pos position.Position // comment position
+
+	// Reports that start inside [fromLine, toLine] are suppressed.
+	fromLine int
+	toLine   int
+
+	checks []string // empty list means "all checks"
+	used   bool
+}
+
+func (s *suppression) matches(line int, checkName string) bool {
+	if line < s.fromLine || line > s.toLine {
+		return false
+	}
+	if len(s.checks) == 0 {
+		return true
+	}
+	for _, c := range s.checks {
+		if c == checkName {
+			return true
+		}
+	}
+	return false
+}
+
+// collectSuppressions finds all suppression comments inside the file.
+func (d *RootWalker) collectSuppressions(rootNode node.Node) {
+	if !bytes.Contains(d.fileContents, []byte("noverify-")) {
+		return
+	}
+
+	walkNode(rootNode, func(w walker.Walkable) bool {
+		n, ok := w.(node.Node)
+		if !ok {
+			return true
+		}
+		ffs := n.GetFreeFloating()
+		if ffs == nil {
+			return true
+		}
+		for ffPos, cs := range *ffs {
+			for _, c := range cs {
+				if c.StringType != freefloating.CommentType || c.Position == nil {
+					continue
+				}
+				if phpdoc.IsPHPDoc(c.Value) {
+					// Doc comment belongs to the node only if it precedes it.
+					if ffPos == freefloating.Start {
+						d.addDocSuppression(c, n)
+					}
+					continue
+				}
+				d.addLineSuppression(c)
+			}
+		}
+		return true
+	})
+}
+
+func (d *RootWalker) addLineSuppression(c freefloating.String) {
+	text := strings.TrimSpace(c.Value)
+	switch {
+	case strings.HasPrefix(text, "//"):
+		text = text[len("//"):]
+	case strings.HasPrefix(text, "#"):
+		text = text[len("#"):]
+	case strings.HasPrefix(text, "/*"):
+		text = strings.TrimSuffix(text[len("/*"):], "*/")
+	}
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, ignoreNextLineMarker) {
+		return
+	}
+	// Marker must be followed by the checks list or end the comment.
+	rest := text[len(ignoreNextLineMarker):]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return
+	}
+	d.suppressions = append(d.suppressions, &suppression{
+		pos:      *c.Position,
+		fromLine: c.Position.EndLine + 1,
+		toLine:   c.Position.EndLine + 1,
+		checks:   parseSuppressedChecks(rest),
+	})
+}
+
+func (d *RootWalker) addDocSuppression(c freefloating.String, n node.Node) {
+	pos := n.GetPosition()
+	if pos == nil {
+		return
+	}
+	for _, part := range phpdoc.Parse(c.Value) {
+		if part.Name != suppressTag {
+			continue
+		}
+		d.suppressions = append(d.suppressions, &suppression{
+			pos:      *c.Position,
+			fromLine: pos.StartLine,
+			toLine:   pos.EndLine,
+			checks:   parseSuppressedChecks(part.ParamsText),
+		})
+	}
+}
+
+// parseSuppressedChecks parses comma or space separated checks list.
+func parseSuppressedChecks(s string) []string {
+	return strings.FieldsFunc(s, func(ch rune) bool {
+		return ch == ',' || ch == ' ' || ch == '\t'
+	})
+}
+
+// suppressed reports whether the report of specified check
+// that starts at a given line should be ignored.
+func (d *RootWalker) suppressed(line int, checkName string) bool {
+	res := false
+	for _, s := range d.suppressions {
+		// Don't stop on the first match, so all
+		// matching suppressions are marked as used.
+		if s.matches(line, checkName) {
+			s.used = true
+			res = true
+		}
+	}
+	return res
+}
+
+// checkEnabled reports whether the check can produce any reports
+// with the current settings.
+func checkEnabled(checkName string) bool {
+	switch checkName {
+	case "unusedSymbol":
+		return UnusedSymbols
+	case "taint":
+		return TaintAnalysis
+	}
+	return true
+}
+
+// reportUnusedSuppressions reports suppressions that don't suppress anything.
+// Must be called after all other reports are collected.
+//
+// Suppressions of checks that are disabled are never used,
+// so it's unknown whether they are needed and they are not reported.
+func (d *RootWalker) reportUnusedSuppressions() {
+	declared := make(map[string]bool)
+	for _, info := range GetDeclaredChecks() {
+		declared[info.Name] = true
+	}
+
+	// Detach suppressions, so these reports can't be suppressed.
+	suppressions := d.suppressions
+	d.suppressions = nil
+	sort.Slice(suppressions, func(i, j int) bool {
+		return suppressions[i].pos.StartPos < suppressions[j].pos.StartPos
+	})
+
+	for _, s := range suppressions {
+		unknown := false
+		disabled := false
+		for _, c := range s.checks {
+			if !declared[c] {
+				unknown = true
+				d.reportAt(s.pos, LevelInformation, "unusedSuppression", nil, "Unknown check %s in suppression comment", c)
+			} else if !checkEnabled(c) {
+				disabled = true
+			}
+		}
+		if !s.used && !unknown && !disabled {
+			d.reportAt(s.pos, LevelInformation, "unusedSuppression", nil, "Suppression comment doesn't suppress anything, it can be removed")
+		}
+	}
+}
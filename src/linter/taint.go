@@ -0,0 +1,616 @@
+package linter
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+
+	"github.com/Levsha-cc/noverify/src/lintdebug"
+	"github.com/Levsha-cc/noverify/src/meta"
+	"github.com/Levsha-cc/noverify/src/solver"
+	"github.com/z7zmey/php-parser/node"
+	"github.com/z7zmey/php-parser/node/expr"
+	"github.com/z7zmey/php-parser/node/expr/assign"
+	"github.com/z7zmey/php-parser/node/expr/binary"
+	"github.com/z7zmey/php-parser/node/expr/cast"
+	"github.com/z7zmey/php-parser/node/scalar"
+	"github.com/z7zmey/php-parser/node/stmt"
+)
+
+// Taint analysis finds user input that reaches dangerous functions (sinks)
+// without passing a sanitizer function:
+//
+//	$id = $_GET['id'];
+//	mysqli_query($db, "SELECT * FROM users WHERE id = $id"); // reported
+//
+// Variables taint is tracked in meta.Scope, functions and methods get
+// summaries (meta.FuncInfo.Taint) that tell whether their return values
+// can contain user input or values of their params.
+//
+// Functions are named in lower case without leading \,
+// methods are named as class::method.
+
+var (
+	// TaintSources are functions that return user input.
+	TaintSources = map[string]struct{}{
+		"getallheaders":          {},
+		"apache_request_headers": {},
+	}
+
+	// TaintSinks are functions and language constructs
+	// that must not get user input.
+	TaintSinks = map[string]struct{}{
+		"mysql_query":         {},
+		"mysqli_query":        {},
+		"mysqli_multi_query":  {},
+		"pg_query":            {},
+		"mysqli::query":       {},
+		"mysqli::multi_query": {},
+		"pdo::query":          {},
+		"pdo::exec":           {},
+		"exec":                {},
+		"system":              {},
+		"passthru":            {},
+		"shell_exec":          {},
+		"popen":               {},
+		"proc_open":           {},
+		"eval":                {},
+		"echo":                {},
+		"print":               {},
+		"include":             {},
+		"include_once":        {},
+		"require":             {},
+		"require_once":        {},
+	}
+
+	// TaintFree are functions that return values computed from their args
+	// that can't contain user input, like lengths, hashes and type checks.
+	// All is_* and ctype_* functions are taint-free too.
+	TaintFree = map[string]struct{}{
+		"count":            {},
+		"sizeof":           {},
+		"strlen":           {},
+		"mb_strlen":        {},
+		"str_word_count":   {},
+		"substr_count":     {},
+		"strpos":           {},
+		"stripos":          {},
+		"strrpos":          {},
+		"mb_strpos":        {},
+		"strcmp":           {},
+		"strcasecmp":       {},
+		"strncmp":          {},
+		"strncasecmp":      {},
+		"hash_equals":      {},
+		"in_array":         {},
+		"array_key_exists": {},
+		"key_exists":       {},
+		"array_sum":        {},
+		"array_product":    {},
+		"preg_match":       {},
+		"preg_match_all":   {},
+		"md5":              {},
+		"sha1":             {},
+		"crc32":            {},
+		"hash":             {},
+		"hash_hmac":        {},
+		"password_hash":    {},
+		"password_verify":  {},
+		"bin2hex":          {},
+		"dechex":           {},
+		"ord":              {},
+		"abs":              {},
+		"round":            {},
+		"floor":            {},
+		"ceil":             {},
+		"intdiv":           {},
+		"number_format":    {},
+		"gettype":          {},
+		"checkdate":        {},
+		"file_exists":      {},
+		"filesize":         {},
+		"filemtime":        {},
+		"json_last_error":  {},
+		"levenshtein":      {},
+		"similar_text":     {},
+		"version_compare":  {},
+	}
+
+	// TaintSanitizers are functions that return values that are safe to pass to sinks.
+	TaintSanitizers = map[string]struct{}{
+		"htmlspecialchars":           {},
+		"htmlentities":               {},
+		"strip_tags":                 {},
+		"intval":                     {},
+		"floatval":                   {},
+		"boolval":                    {},
+		"escapeshellarg":             {},
+		"escapeshellcmd":             {},
+		"addslashes":                 {},
+		"urlencode":                  {},
+		"rawurlencode":               {},
+		"mysqli_real_escape_string":  {},
+		"pg_escape_string":           {},
+		"mysqli::real_escape_string": {},
+		"pdo::quote":                 {},
+	}
+)
+
+// taintConfigVersion returns a string that identifies the taint analysis config,
+// so the cached function summaries are not used with another config.
+func taintConfigVersion() string {
+	if !TaintAnalysis {
+		return ""
+	}
+
+	h := fnv.New64a()
+	for _, set := range []map[string]struct{}{TaintSources, TaintSinks, TaintSanitizers, TaintFree} {
+		for _, name := range sortedSet(set) {
+			fmt.Fprintf(h, "%s,", name)
+		}
+		fmt.Fprintf(h, ";")
+	}
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+// maxTaintPasses limits the number of passes that propagate
+// taint summaries through the nested calls.
+const maxTaintPasses = 10
+
+// UpdateTaintSummaries propagates taint summaries through the calls
+// of the functions and methods declared in the files.
+//
+// Summaries computed during indexing can't use the summaries of other
+// user functions, so they only describe one call level. After indexing,
+// the summaries are cleared and the files are analyzed again until
+// the summaries stop changing.
+func UpdateTaintSummaries(readFileNamesFunc ReadCallback) {
+	if !TaintAnalysis || !meta.IsIndexingComplete() {
+		return
+	}
+
+	resetTaintSummaries(readFileNamesFunc)
+	for i := 0; i < maxTaintPasses; i++ {
+		if !applyTaintSummaries(collectTaintSummaries(readFileNamesFunc)) {
+			return
+		}
+	}
+	lintdebug.Send("Taint summaries did not converge in %d passes", maxTaintPasses)
+}
+
+// resetTaintSummaries clears the summaries of the functions and methods declared in the files.
+// Passes that start from the empty summaries don't get stuck on the imprecise summaries
+// of recursive functions.
+func resetTaintSummaries(readFileNamesFunc ReadCallback) {
+	filenamesCh := make(chan FileInfo)
+
+	go func() {
+		readFileNamesFunc(filenamesCh)
+		close(filenamesCh)
+	}()
+
+	meta.Info.Lock()
+	defer meta.Info.Unlock()
+
+	for f := range filenamesCh {
+		m := meta.Info.GetMetaForFile(f.Filename)
+		for nm := range m.Functions {
+			meta.Info.SetFunctionTaintNonLocked(f.Filename, nm, 0)
+		}
+		for _, classes := range []meta.ClassesMap{m.Classes, m.Traits} {
+			for className, class := range classes {
+				for nm := range class.Methods {
+					meta.Info.SetMethodTaintNonLocked(f.Filename, className, nm, 0)
+				}
+			}
+		}
+	}
+}
+
+// collectTaintSummaries analyzes the files using the current summaries
+// and returns the functions and classes declared in them.
+func collectTaintSummaries(readFileNamesFunc ReadCallback) map[string]fileMeta {
+	filenamesCh := make(chan FileInfo)
+
+	go func() {
+		readFileNamesFunc(filenamesCh)
+		close(filenamesCh)
+	}()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	summaries := make(map[string]fileMeta)
+
+	workers := MaxConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range filenamesCh {
+				_, w, err := parseContents(f.Filename, f.Contents, nil, true)
+				if err != nil {
+					// The error is already logged during indexing.
+					continue
+				}
+				mu.Lock()
+				summaries[f.Filename] = w.meta
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return summaries
+}
+
+// applyTaintSummaries updates the summaries in meta.Info
+// and reports whether any of them has changed.
+func applyTaintSummaries(summaries map[string]fileMeta) bool {
+	meta.Info.Lock()
+	defer meta.Info.Unlock()
+
+	changed := false
+	for filename, m := range summaries {
+		for nm, fn := range m.Functions {
+			if meta.Info.SetFunctionTaintNonLocked(filename, nm, fn.Taint) {
+				changed = true
+			}
+		}
+		for _, classes := range []meta.ClassesMap{m.Classes, m.Traits} {
+			for className, class := range classes {
+				for nm, fn := range class.Methods {
+					if meta.Info.SetMethodTaintNonLocked(filename, className, nm, fn.Taint) {
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	return changed
+}
+
+func taintFuncName(fqName string) string {
+	return strings.ToLower(strings.TrimPrefix(fqName, `\`))
+}
+
+func taintMethodName(className, methodName string) string {
+	return taintFuncName(className) + "::" + strings.ToLower(methodName)
+}
+
+// handleTaint tracks taint of the variables assigned by n and reports tainted sinks.
+func (b *BlockWalker) handleTaint(n node.Node) {
+	switch n := n.(type) {
+	case *assign.Assign:
+		b.assignTaint(n.Variable, b.exprTaint(n.Expression), true)
+	case *assign.Reference:
+		b.assignTaint(n.Variable, b.exprTaint(n.Expression), true)
+	case *assign.Concat:
+		b.assignTaint(n.Variable, b.exprTaint(n.Expression), false)
+	case *stmt.Foreach:
+		t := b.exprTaint(n.Expr)
+		b.assignTaint(n.Key, t, true)
+		b.assignTaint(n.Variable, t, true)
+	case *stmt.AltForeach:
+		t := b.exprTaint(n.Expr)
+		b.assignTaint(n.Key, t, true)
+		b.assignTaint(n.Variable, t, true)
+	}
+
+	if meta.IsIndexingComplete() {
+		b.checkTaintSinks(n)
+	}
+}
+
+// assignTaint sets taint t for the variables of v. If replace is false,
+// t is added to the current variables taint.
+func (b *BlockWalker) assignTaint(v node.Node, t meta.Taint, replace bool) {
+	switch v := v.(type) {
+	case *expr.Variable:
+		id, ok := v.VarName.(*node.Identifier)
+		if !ok {
+			return
+		}
+		if replace {
+			b.ctx.sc.SetVarTaint(id.Value, t)
+		} else {
+			b.ctx.sc.AddVarTaint(id.Value, t)
+		}
+	case *expr.Reference:
+		b.assignTaint(v.Variable, t, replace)
+	case *expr.ArrayDimFetch:
+		// Other elements of the array keep their taint.
+		b.assignTaint(v.Variable, t, false)
+	case *expr.List:
+		for _, item := range v.Items {
+			if item, ok := item.(*expr.ArrayItem); ok {
+				b.assignTaint(item.Val, t, replace)
+			}
+		}
+	case *expr.ShortList:
+		for _, item := range v.Items {
+			if item, ok := item.(*expr.ArrayItem); ok {
+				b.assignTaint(item.Val, t, replace)
+			}
+		}
+	}
+}
+
+// exprTaint returns taint of the values of expression n.
+func (b *BlockWalker) exprTaint(n node.Node) meta.Taint {
+	switch n := n.(type) {
+	case *expr.Variable:
+		id, ok := n.VarName.(*node.Identifier)
+		if !ok {
+			return 0
+		}
+		if _, ok := taintedSuperGlobals[id.Value]; ok {
+			return meta.TaintInput
+		}
+		return b.ctx.sc.GetVarTaint(id.Value)
+	case *expr.ArrayDimFetch:
+		if isSafeServerVar(n) {
+			return 0
+		}
+		return b.exprTaint(n.Variable)
+	case *expr.Array:
+		return b.itemsTaint(n.Items)
+	case *expr.ShortArray:
+		return b.itemsTaint(n.Items)
+	case *binary.Concat:
+		return b.exprTaint(n.Left) | b.exprTaint(n.Right)
+	case *binary.Coalesce:
+		return b.exprTaint(n.Left) | b.exprTaint(n.Right)
+	case *scalar.Encapsed:
+		return b.partsTaint(n.Parts)
+	case *scalar.Heredoc:
+		return b.partsTaint(n.Parts)
+	case *expr.Ternary:
+		t := b.exprTaint(n.IfFalse)
+		if n.IfTrue != nil {
+			return t | b.exprTaint(n.IfTrue)
+		}
+		return t | b.exprTaint(n.Condition)
+	case *assign.Assign:
+		return b.exprTaint(n.Expression)
+	case *assign.Reference:
+		return b.exprTaint(n.Expression)
+	case *assign.Concat:
+		return b.exprTaint(n.Variable) | b.exprTaint(n.Expression)
+	case *cast.String:
+		return b.exprTaint(n.Expr)
+	case *cast.Array:
+		return b.exprTaint(n.Expr)
+	case *expr.FunctionCall:
+		return b.funcCallTaint(n)
+	case *expr.MethodCall:
+		return b.methodCallTaint(n)
+	case *expr.StaticCall:
+		return b.staticCallTaint(n)
+	}
+
+	// Numbers, booleans and constants are not tainted.
+	return 0
+}
+
+func (b *BlockWalker) itemsTaint(items []node.Node) meta.Taint {
+	var t meta.Taint
+	for _, item := range items {
+		if item, ok := item.(*expr.ArrayItem); ok {
+			t |= b.exprTaint(item.Val)
+		}
+	}
+	return t
+}
+
+func (b *BlockWalker) partsTaint(parts []node.Node) meta.Taint {
+	var t meta.Taint
+	for _, p := range parts {
+		t |= b.exprTaint(p)
+	}
+	return t
+}
+
+func (b *BlockWalker) argsTaint(args []node.Node) meta.Taint {
+	var t meta.Taint
+	for _, arg := range args {
+		if arg, ok := arg.(*node.Argument); ok {
+			t |= b.exprTaint(arg.Expr)
+		}
+	}
+	return t
+}
+
+func (b *BlockWalker) funcCallTaint(e *expr.FunctionCall) meta.Taint {
+	fqName, fn, defined := b.getFunction(e.Function)
+	if fqName == "" {
+		return b.argsTaint(e.ArgumentList.Arguments)
+	}
+	_, internal := meta.GetInternalFunctionInfo(fqName)
+	return b.callTaint(taintFuncName(fqName), fn, defined && !internal, e.ArgumentList.Arguments)
+}
+
+func (b *BlockWalker) methodCallTaint(e *expr.MethodCall) meta.Taint {
+	fn, implClass, found := b.findTaintMethod(e)
+	if !found {
+		return b.argsTaint(e.ArgumentList.Arguments)
+	}
+	methodName := e.Method.(*node.Identifier).Value
+	return b.callTaint(taintMethodName(implClass, methodName), fn, !meta.IsInternalClass(implClass), e.ArgumentList.Arguments)
+}
+
+func (b *BlockWalker) staticCallTaint(e *expr.StaticCall) meta.Taint {
+	fn, implClass, found := b.findTaintStaticMethod(e)
+	if !found {
+		return b.argsTaint(e.ArgumentList.Arguments)
+	}
+	methodName := e.Call.(*node.Identifier).Value
+	return b.callTaint(taintMethodName(implClass, methodName), fn, !meta.IsInternalClass(implClass), e.ArgumentList.Arguments)
+}
+
+func (b *BlockWalker) findTaintMethod(e *expr.MethodCall) (fn meta.FuncInfo, implClass string, found bool) {
+	id, ok := e.Method.(*node.Identifier)
+	if !ok || !meta.IsIndexingComplete() {
+		return fn, "", false
+	}
+	solver.ExprTypeCustom(b.ctx.sc, b.r.st, e.Variable, b.ctx.customTypes).Iterate(func(typ string) {
+		if !found {
+			fn, implClass, found = solver.FindMethod(typ, id.Value)
+		}
+	})
+	return fn, implClass, found
+}
+
+func (b *BlockWalker) findTaintStaticMethod(e *expr.StaticCall) (fn meta.FuncInfo, implClass string, found bool) {
+	id, ok := e.Call.(*node.Identifier)
+	if !ok || !meta.IsIndexingComplete() {
+		return fn, "", false
+	}
+	className, ok := solver.GetClassName(b.r.st, e.Class)
+	if !ok {
+		return fn, "", false
+	}
+	return solver.FindMethod(className, id.Value)
+}
+
+// callTaint returns taint of a call result. Summaries of the called functions are only
+// used after indexing (see UpdateTaintSummaries), unknown and builtin functions
+// propagate taint of all args.
+func (b *BlockWalker) callTaint(name string, fn meta.FuncInfo, haveSummary bool, args []node.Node) meta.Taint {
+	if _, ok := TaintSanitizers[name]; ok {
+		return 0
+	}
+	if isTaintFree(name) {
+		return 0
+	}
+	if _, ok := TaintSources[name]; ok {
+		return meta.TaintInput
+	}
+	if name == "sprintf" {
+		if t, ok := b.sprintfTaint(args); ok {
+			return t
+		}
+	}
+	if !haveSummary || !meta.IsIndexingComplete() {
+		return b.argsTaint(args)
+	}
+
+	t := fn.Taint & meta.TaintInput
+	for i, arg := range args {
+		arg, ok := arg.(*node.Argument)
+		if !ok {
+			continue
+		}
+		param := i
+		if param >= len(fn.Params) {
+			if len(fn.Params) == 0 || !fn.Params[len(fn.Params)-1].IsVariadic {
+				break
+			}
+			param = len(fn.Params) - 1
+		}
+		if fn.Taint.HasParam(param) {
+			t |= b.exprTaint(arg.Expr)
+		}
+	}
+	return t
+}
+
+func isTaintFree(name string) bool {
+	if _, ok := TaintFree[name]; ok {
+		return true
+	}
+	return strings.HasPrefix(name, "is_") || strings.HasPrefix(name, "ctype_")
+}
+
+// sprintfTaint returns taint of sprintf call result if its format is known.
+// Only the args formatted as text can pass user input to the result.
+func (b *BlockWalker) sprintfTaint(args []node.Node) (meta.Taint, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	for _, arg := range args {
+		if arg, ok := arg.(*node.Argument); !ok || arg.Variadic {
+			return 0, false
+		}
+	}
+	format, ok := args[0].(*node.Argument).Expr.(*scalar.String)
+	if !ok {
+		return 0, false
+	}
+	directives, err := parsePrintfFormat(interpretString(format.Value))
+	if err != nil {
+		return 0, false
+	}
+
+	var t meta.Taint
+	for _, argNum := range directives.textArgs {
+		if argNum < len(args) {
+			t |= b.exprTaint(args[argNum].(*node.Argument).Expr)
+		}
+	}
+	return t, true
+}
+
+func (b *BlockWalker) checkTaintSinks(n node.Node) {
+	switch n := n.(type) {
+	case *stmt.Echo:
+		for _, e := range n.Exprs {
+			b.checkTaintSink("echo", e)
+		}
+	case *expr.Print:
+		b.checkTaintSink("print", n.Expr)
+	case *expr.Eval:
+		b.checkTaintSink("eval", n.Expr)
+	case *expr.Include:
+		b.checkTaintSink("include", n.Expr)
+	case *expr.IncludeOnce:
+		b.checkTaintSink("include_once", n.Expr)
+	case *expr.Require:
+		b.checkTaintSink("require", n.Expr)
+	case *expr.RequireOnce:
+		b.checkTaintSink("require_once", n.Expr)
+	case *expr.ShellExec:
+		for _, p := range n.Parts {
+			b.checkTaintSink("shell_exec", p)
+		}
+
+	case *expr.FunctionCall:
+		fqName, _, _ := b.getFunction(n.Function)
+		if fqName != "" {
+			b.checkTaintSinkArgs(taintFuncName(fqName), n.ArgumentList.Arguments)
+		}
+	case *expr.MethodCall:
+		if _, implClass, found := b.findTaintMethod(n); found {
+			name := taintMethodName(implClass, n.Method.(*node.Identifier).Value)
+			b.checkTaintSinkArgs(name, n.ArgumentList.Arguments)
+		}
+	case *expr.StaticCall:
+		if _, implClass, found := b.findTaintStaticMethod(n); found {
+			name := taintMethodName(implClass, n.Call.(*node.Identifier).Value)
+			b.checkTaintSinkArgs(name, n.ArgumentList.Arguments)
+		}
+	}
+}
+
+func (b *BlockWalker) checkTaintSinkArgs(name string, args []node.Node) {
+	if _, ok := TaintSinks[name]; !ok {
+		return
+	}
+	for _, arg := range args {
+		if arg, ok := arg.(*node.Argument); ok && b.exprTaint(arg.Expr).IsInput() {
+			b.r.Report(arg, LevelWarning, "taint", "User input is passed to %s without sanitizing", name)
+		}
+	}
+}
+
+func (b *BlockWalker) checkTaintSink(name string, n node.Node) {
+	if _, ok := TaintSinks[name]; !ok {
+		return
+	}
+	if b.exprTaint(n).IsInput() {
+		b.r.Report(n, LevelWarning, "taint", "User input is passed to %s without sanitizing", name)
+	}
+}
@@ -0,0 +1,165 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/Levsha-cc/noverify/src/meta"
+	"github.com/Levsha-cc/noverify/src/solver"
+)
+
+// Type compatibility checks are intentionally loose: PHP converts scalars
+// to each other in non-strict mode and types inferred by the solver are not
+// always precise, so only clearly incompatible types are reported.
+
+// nullType is a resolved type of null value.
+var nullType = map[string]struct{}{"null": {}}
+
+// resolveTypesStrict resolves all lazy types from m.
+// Returns nil if m is empty or some of its types can't be resolved.
+func resolveTypesStrict(curStaticClass string, m *meta.TypesMap) map[string]struct{} {
+	if m.IsEmpty() {
+		return nil
+	}
+
+	res := make(map[string]struct{}, m.Len())
+	unknown := false
+	m.Iterate(func(typ string) {
+		if unknown {
+			return
+		}
+		// Resolve types one by one, so unresolved types are not silently dropped.
+		for t := range solver.ResolveTypes(curStaticClass, meta.NewTypesMap(typ), make(map[string]struct{})) {
+			if t == "mixed" {
+				unknown = true
+			}
+			res[t] = struct{}{}
+		}
+	})
+
+	if unknown {
+		return nil
+	}
+	return res
+}
+
+// typesCompatible reports whether at least one of actual types is accepted
+// by at least one of expected types.
+//
+// Types that are not known to the checker (mixed, unknown classes, etc)
+// make any types compatible.
+func typesCompatible(expected, actual map[string]struct{}) bool {
+	for typ := range expected {
+		if !isKnownType(typ) {
+			return true
+		}
+	}
+	for typ := range actual {
+		if !isKnownType(typ) {
+			return true
+		}
+	}
+
+	for typ := range actual {
+		for want := range expected {
+			if typeAccepts(want, typ) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typeAccepts reports whether a value of typ type can be used where want type is expected.
+// Both types must be resolved and known.
+func typeAccepts(want, typ string) bool {
+	if want == typ {
+		return true
+	}
+	if typeMayBe(typ, want) {
+		return true
+	}
+
+	switch {
+	case isScalarType(want):
+		if isScalarType(typ) {
+			return true
+		}
+		if want == "string" && isClassType(typ) {
+			_, _, ok := solver.FindMethod(typ, "__toString")
+			return ok
+		}
+		return false
+	case isArrayType(want):
+		return isArrayType(typ)
+	case want == "iterable":
+		return isArrayType(typ) || isClassType(typ) && solver.InstanceOf(typ, `\Traversable`)
+	case want == "callable":
+		if typ == "string" || isArrayType(typ) {
+			return true
+		}
+		if isClassType(typ) {
+			_, _, ok := solver.FindMethod(typ, "__invoke")
+			return ok || solver.InstanceOf(typ, `\Closure`)
+		}
+		return false
+	case want == "object":
+		return isClassType(typ)
+	case isClassType(want):
+		return isClassType(typ) && solver.InstanceOf(typ, want)
+	}
+
+	return false
+}
+
+// typeMayBe reports whether a value of typ pseudo-type can be of want type,
+// like an object that can be an instance of any class.
+// Such values may be compatible, so they are not reported.
+func typeMayBe(typ, want string) bool {
+	switch typ {
+	case "object":
+		return isClassType(want) || want == "callable" || want == "iterable"
+	case "callable":
+		return want == `\Closure` || want == "string" || want == "object" || isArrayType(want)
+	case "iterable":
+		return isArrayType(want) || want == "object" || isClassType(want) && solver.InstanceOf(want, `\Traversable`)
+	}
+	return false
+}
+
+// isKnownType reports whether typ can be checked by typeAccepts.
+func isKnownType(typ string) bool {
+	switch {
+	case isScalarType(typ), isArrayType(typ):
+		return true
+	case isClassType(typ):
+		_, ok := meta.Info.GetClass(typ)
+		return ok
+	}
+
+	switch typ {
+	case "null", "iterable", "callable", "object", "resource":
+		return true
+	}
+	return false
+}
+
+func isScalarType(typ string) bool {
+	switch typ {
+	case "int", "integer", "float", "double", "string", "bool", "boolean", "true", "false":
+		return true
+	}
+	return false
+}
+
+func isArrayType(typ string) bool {
+	return typ == "array" || typ == "empty_array" || strings.HasSuffix(typ, "[]")
+}
+
+func isClassType(typ string) bool {
+	return strings.HasPrefix(typ, `\`) && !strings.HasSuffix(typ, "[]")
+}
+
+// formatTypes returns resolved types in the same format as meta.TypesMap does.
+func formatTypes(types map[string]struct{}) string {
+	return meta.NewTypesMapFromMap(types).String()
+}
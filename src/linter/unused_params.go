@@ -0,0 +1,177 @@
+package linter
+
+import (
+	"strings"
+
+	"github.com/Levsha-cc/noverify/src/meta"
+	"github.com/Levsha-cc/noverify/src/solver"
+	"github.com/z7zmey/php-parser/node"
+	"github.com/z7zmey/php-parser/node/expr"
+)
+
+// unusedParamsCheck describes the params of a function that are reported if they are never used.
+type unusedParamsCheck struct {
+	params     []node.Node // *node.Parameter nodes
+	inherited  int         // number of leading params required by the overridden method signature
+	positional bool        // whether only the params after the last used one can be removed, like in callbacks
+}
+
+// newUnusedParamsCheck returns a check for the params of a function with stmts body
+// or nil if the params can't be checked.
+//
+// Functions without statements are stubs or no-op implementations,
+// so their params are not reported.
+func newUnusedParamsCheck(params, stmts []node.Node) *unusedParamsCheck {
+	if len(params) == 0 || len(stmts) == 0 || readsAllParams(stmts) {
+		return nil
+	}
+	return &unusedParamsCheck{params: params}
+}
+
+// readsAllParams reports whether function body reads its params
+// without referring them by name, e.g. using func_get_args().
+func readsAllParams(stmts []node.Node) bool {
+	return funcBodyContains(stmts, func(n node.Node) bool {
+		call, ok := n.(*expr.FunctionCall)
+		if !ok {
+			return false
+		}
+		switch meta.NameNodeToString(call.Function) {
+		case "func_get_args", `\func_get_args`, "func_get_arg", `\func_get_arg`, "get_defined_vars", `\get_defined_vars`:
+			return true
+		}
+		return false
+	})
+}
+
+// inheritedParamsCount returns the number of params of the current class method nm
+// that are required to satisfy the signature of the parent class or interface method.
+func (d *RootWalker) inheritedParamsCount(nm string) int {
+	class, ok := meta.Info.GetClass(d.st.CurrentClass)
+	if !ok {
+		return 0
+	}
+
+	count := 0
+	inherit := func(m meta.FuncInfo) {
+		// Constructors signatures are only enforced by abstract declarations.
+		if strings.EqualFold(nm, "__construct") && !m.Abstract {
+			return
+		}
+		if len(m.Params) > count {
+			count = len(m.Params)
+		}
+	}
+
+	if class.Parent != "" {
+		if m, _, ok := solver.FindMethod(class.Parent, nm); ok && m.AccessLevel != meta.Private {
+			inherit(m)
+		}
+	}
+	for iface := range collectInterfaces(d.st.CurrentClass) {
+		if m, _, ok := solver.FindMethod(iface, nm); ok {
+			inherit(m)
+		}
+	}
+
+	return count
+}
+
+// overriddenMethods maps classes to the lower case names of their methods
+// that are overridden in the subclasses. It's rebuilt when indexing is complete.
+var overriddenMethods map[string]map[string]struct{}
+
+func init() {
+	meta.OnIndexingComplete(collectOverriddenMethods)
+}
+
+func collectOverriddenMethods() {
+	overridden := make(map[string]map[string]struct{})
+	meta.Info.IterateClasses(func(className string, class meta.ClassInfo) {
+		if len(class.Methods) == 0 {
+			return
+		}
+		visited := map[string]struct{}{className: {}}
+		for parent := class.Parent; parent != ""; {
+			if _, ok := visited[parent]; ok {
+				break
+			}
+			visited[parent] = struct{}{}
+
+			methods := overridden[parent]
+			if methods == nil {
+				methods = make(map[string]struct{})
+				overridden[parent] = methods
+			}
+			for nm := range class.Methods {
+				methods[strings.ToLower(nm)] = struct{}{}
+			}
+
+			parentClass, ok := meta.Info.GetClass(parent)
+			if !ok {
+				break
+			}
+			parent = parentClass.Parent
+		}
+	})
+	overriddenMethods = overridden
+}
+
+// isOverridden reports whether the method nm of the class is overridden in any known subclass.
+func isOverridden(className, nm string) bool {
+	_, ok := overriddenMethods[className][strings.ToLower(nm)]
+	return ok
+}
+
+// trackUnusedParams marks the params of check as unused until they are read.
+func (b *BlockWalker) trackUnusedParams(check *unusedParamsCheck) {
+	if check == nil {
+		return
+	}
+	b.unusedParams = check
+	b.paramVars = make(map[node.Node]int, len(check.params))
+	for i, p := range check.params {
+		v := p.(*node.Parameter).Variable.(*expr.Variable)
+		name := v.VarName.(*node.Identifier).Value
+		b.paramVars[v] = i
+		b.unusedVars[name] = append(b.unusedVars[name], v)
+	}
+}
+
+func (b *BlockWalker) flushUnusedParams() {
+	check := b.unusedParams
+	if check == nil {
+		return
+	}
+
+	unused := make([]bool, len(check.params))
+	for _, nodes := range b.unusedVars {
+		for _, n := range nodes {
+			if i, ok := b.paramVars[n]; ok {
+				unused[i] = true
+			}
+		}
+	}
+
+	from := check.inherited
+	if check.positional {
+		for i := len(unused) - 1; i >= from; i-- {
+			if !unused[i] {
+				from = i + 1
+				break
+			}
+		}
+	}
+
+	for i := from; i < len(check.params); i++ {
+		if !unused[i] {
+			continue
+		}
+		v := check.params[i].(*node.Parameter).Variable.(*expr.Variable)
+		name := v.VarName.(*node.Identifier).Value
+		if IsDiscardVar(name) {
+			continue
+		}
+		b.r.Report(v, LevelUnused, "unusedParam", "Unused parameter $%s", name)
+	}
+}
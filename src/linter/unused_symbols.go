@@ -0,0 +1,347 @@
+package linter
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/Levsha-cc/noverify/src/meta"
+	"github.com/Levsha-cc/noverify/src/phpdoc"
+	"github.com/Levsha-cc/noverify/src/solver"
+	"github.com/z7zmey/php-parser/node"
+	"github.com/z7zmey/php-parser/node/expr"
+	"github.com/z7zmey/php-parser/node/name"
+	"github.com/z7zmey/php-parser/node/scalar"
+	"github.com/z7zmey/php-parser/node/stmt"
+	"github.com/z7zmey/php-parser/walker"
+)
+
+// Unused symbols are found in two steps: every analyzed file records
+// the symbols it declares and references, and when all files are analyzed,
+// UnusedSymbolReports returns declarations that were never referenced.
+//
+// Methods and class constants are matched by names only, without resolving
+// the object types. Every string literal that looks like a symbol name is
+// treated as a reference too, so callbacks like 'my_func' or [$obj, 'method']
+// are handled. This can only cause false negatives.
+
+// unusedSymbol is a declaration that is reported unless its key is referenced.
+type unusedSymbol struct {
+	key    string
+	report *Report
+}
+
+// symbolsUsage collects symbols declared and referenced in a single file.
+type symbolsUsage struct {
+	st    *meta.ClassParseState
+	refs  map[string]struct{}
+	decls []unusedSymbol
+
+	// apiClass is true if the current class is marked with @api,
+	// so all its members are considered used.
+	apiClass bool
+}
+
+var projectSymbols struct {
+	sync.Mutex
+	refs  map[string]struct{}
+	decls []unusedSymbol
+}
+
+func newSymbolsUsage(st *meta.ClassParseState) *symbolsUsage {
+	return &symbolsUsage{
+		st:   st,
+		refs: make(map[string]struct{}),
+	}
+}
+
+func functionSymbol(name string) string   { return "function " + strings.ToLower(name) }
+func classSymbol(name string) string      { return "class " + strings.ToLower(name) }
+func methodSymbol(name string) string     { return "method " + strings.ToLower(name) }
+func classConstSymbol(name string) string { return "const " + name }
+
+// flush merges symbols of the file into the project-wide collection.
+func (u *symbolsUsage) flush() {
+	projectSymbols.Lock()
+	defer projectSymbols.Unlock()
+
+	if projectSymbols.refs == nil {
+		projectSymbols.refs = make(map[string]struct{})
+	}
+	for key := range u.refs {
+		projectSymbols.refs[key] = struct{}{}
+	}
+	projectSymbols.decls = append(projectSymbols.decls, u.decls...)
+}
+
+// UnusedSymbolReports returns reports for symbols declared in the analyzed files
+// that are not referenced by any of them and resets the collected symbols.
+//
+// It must be called after all files are analyzed with UnusedSymbols enabled.
+func UnusedSymbolReports() []*Report {
+	projectSymbols.Lock()
+	defer projectSymbols.Unlock()
+
+	var reports []*Report
+	for _, s := range projectSymbols.decls {
+		if _, ok := projectSymbols.refs[s.key]; !ok {
+			reports = append(reports, s.report)
+		}
+	}
+
+	projectSymbols.refs = nil
+	projectSymbols.decls = nil
+	return reports
+}
+
+func (u *symbolsUsage) use(key string) {
+	u.refs[key] = struct{}{}
+}
+
+// markUsed records symbol references made by the node.
+func (u *symbolsUsage) markUsed(w walker.Walkable) {
+	switch n := w.(type) {
+	case *expr.FunctionCall:
+		u.useFunction(n.Function)
+	case *expr.New:
+		u.useClass(n.Class)
+	case *expr.StaticCall:
+		u.useClass(n.Class)
+		u.useMethod(n.Call)
+	case *expr.MethodCall:
+		u.useMethod(n.Method)
+	case *expr.ClassConstFetch:
+		u.useClass(n.Class)
+		if id, ok := n.ConstantName.(*node.Identifier); ok {
+			u.use(classConstSymbol(id.Value))
+		}
+	case *expr.StaticPropertyFetch:
+		u.useClass(n.Class)
+	case *expr.InstanceOf:
+		u.useClass(n.Class)
+	case *stmt.Try:
+		// Catch nodes are not walked, only their statements are.
+		for _, c := range n.Catches {
+			for _, typ := range c.(*stmt.Catch).Types {
+				u.useClass(typ)
+			}
+		}
+	case *stmt.Class:
+		if n.Extends != nil {
+			u.useClass(n.Extends.ClassName)
+		}
+		if n.Implements != nil {
+			for _, iface := range n.Implements.InterfaceNames {
+				u.useClass(iface)
+			}
+		}
+	case *stmt.Interface:
+		if n.Extends != nil {
+			for _, iface := range n.Extends.InterfaceNames {
+				u.useClass(iface)
+			}
+		}
+	case *stmt.TraitUse:
+		for _, tr := range n.Traits {
+			u.useClass(tr)
+		}
+	case *stmt.Function:
+		u.useSignature(n.Params, n.ReturnType)
+	case *stmt.ClassMethod:
+		u.useSignature(n.Params, n.ReturnType)
+	case *expr.Closure:
+		u.useSignature(n.Params, n.ReturnType)
+	case *scalar.String:
+		u.useString(unquote(n.Value))
+	}
+}
+
+func (u *symbolsUsage) useFunction(n node.Node) {
+	switch nm := n.(type) {
+	case *name.Name:
+		nameStr := meta.NameToString(nm)
+		firstPart := nm.Parts[0].(*name.NamePart).Value
+		if alias, ok := u.st.FunctionUses[firstPart]; ok {
+			if len(nm.Parts) == 1 {
+				u.use(functionSymbol(alias))
+			} else {
+				u.use(functionSymbol(alias + `\` + meta.NamePartsToString(nm.Parts[1:])))
+			}
+			return
+		}
+		// Unqualified calls fall back to the global namespace.
+		u.use(functionSymbol(u.st.Namespace + `\` + nameStr))
+		u.use(functionSymbol(`\` + nameStr))
+	case *name.FullyQualified:
+		u.use(functionSymbol(meta.FullyQualifiedToString(nm)))
+	}
+}
+
+func (u *symbolsUsage) useClass(n node.Node) {
+	if className, ok := solver.GetClassName(u.st, n); ok {
+		u.use(classSymbol(className))
+	}
+}
+
+func (u *symbolsUsage) useMethod(n node.Node) {
+	if id, ok := n.(*node.Identifier); ok {
+		u.use(methodSymbol(id.Value))
+	}
+}
+
+// useSignature records classes used in type hints and parameter default values.
+// Function bodies are walked separately.
+func (u *symbolsUsage) useSignature(params []node.Node, returnType node.Node) {
+	for _, p := range params {
+		p := p.(*node.Parameter)
+		u.useTypeHint(p.VariableType)
+		walkNode(p.DefaultValue, func(w walker.Walkable) bool {
+			u.markUsed(w)
+			return true
+		})
+	}
+	u.useTypeHint(returnType)
+}
+
+func (u *symbolsUsage) useTypeHint(n node.Node) {
+	if nullable, ok := n.(*node.Nullable); ok {
+		n = nullable.Expr
+	}
+	switch n.(type) {
+	case *name.Name, *name.FullyQualified, *name.Relative:
+		u.useClass(n)
+	}
+}
+
+// useString records a string that can be a function, class or method name.
+func (u *symbolsUsage) useString(s string) {
+	s = strings.Replace(s, `\\`, `\`, -1)
+	className := s
+	methodName := ""
+	if idx := strings.Index(s, "::"); idx != -1 {
+		className, methodName = s[:idx], s[idx+len("::"):]
+		if !isSymbolName(methodName) {
+			return
+		}
+	}
+	if !isSymbolName(strings.TrimPrefix(className, `\`)) {
+		return
+	}
+
+	fqName := `\` + strings.TrimPrefix(className, `\`)
+	u.use(classSymbol(fqName))
+	if methodName != "" {
+		u.use(methodSymbol(methodName))
+		return
+	}
+	u.use(functionSymbol(fqName))
+	if !strings.Contains(className, `\`) {
+		u.use(methodSymbol(className))
+	}
+}
+
+// isSymbolName reports whether s is a possibly namespaced PHP identifier.
+func isSymbolName(s string) bool {
+	for _, part := range strings.Split(s, `\`) {
+		if part == "" {
+			return false
+		}
+		for i, ch := range part {
+			switch {
+			case ch == '_', ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= 0x80:
+			case ch >= '0' && ch <= '9' && i != 0:
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// declareSymbol registers a declaration that is reported as unused
+// unless some of the analyzed files references it.
+//
+// Declarations marked with @api or matching UnusedSymbolsAllowRegex
+// are entry points that are called implicitly and never reported.
+func (d *RootWalker) declareSymbol(key, symbolName, doc string, n node.Node, msg string) {
+	if hasAPITag(doc) {
+		return
+	}
+	if UnusedSymbolsAllowRegex != nil && UnusedSymbolsAllowRegex.MatchString(symbolName) {
+		return
+	}
+
+	// The report is created right away, so suppressions are applied
+	// while the file is being analyzed.
+	reportsCount := len(d.reports)
+	d.Report(n, LevelUnused, "unusedSymbol", msg, symbolName)
+	if len(d.reports) == reportsCount {
+		return
+	}
+	r := d.reports[reportsCount]
+	d.reports = d.reports[:reportsCount]
+	d.symbols.decls = append(d.symbols.decls, unusedSymbol{key: key, report: r})
+}
+
+func (d *RootWalker) declareClassSymbol(kind, doc string, n node.Node) {
+	d.symbols.apiClass = hasAPITag(doc)
+	d.declareSymbol(classSymbol(d.st.CurrentClass), d.st.CurrentClass, doc, n, "Unused "+kind+" %s")
+}
+
+func (d *RootWalker) declareMethodSymbol(meth *stmt.ClassMethod, nm string) {
+	// Magic methods are called implicitly and methods declared
+	// in the parent classes or interfaces are called through them.
+	if d.symbols.apiClass || strings.HasPrefix(nm, "__") || inheritsMethod(d.st.CurrentClass, nm) {
+		return
+	}
+	d.declareSymbol(methodSymbol(nm), d.st.CurrentClass+"::"+nm, meth.PhpDocComment, meth.MethodName, "Unused public method %s")
+}
+
+func (d *RootWalker) declareClassConstSymbol(c *stmt.Constant, nm string) {
+	if d.symbols.apiClass {
+		return
+	}
+	d.declareSymbol(classConstSymbol(nm), d.st.CurrentClass+"::"+nm, c.PhpDocComment, c.ConstantName, "Unused class constant %s")
+}
+
+// inheritsMethod reports whether the class gets the method declaration
+// from one of its parent classes or implemented interfaces.
+func inheritsMethod(className, methodName string) bool {
+	visited := make(map[string]struct{})
+	for {
+		if _, ok := visited[className]; ok {
+			return false
+		}
+		visited[className] = struct{}{}
+
+		class, ok := meta.Info.GetClass(className)
+		if !ok {
+			return false
+		}
+		for iface := range class.Interfaces {
+			if _, _, ok := solver.FindMethod(iface, methodName); ok {
+				return true
+			}
+		}
+		for _, iface := range class.ParentInterfaces {
+			if _, _, ok := solver.FindMethod(iface, methodName); ok {
+				return true
+			}
+		}
+		if class.Parent == "" {
+			return false
+		}
+		if _, _, ok := solver.FindMethod(class.Parent, methodName); ok {
+			return true
+		}
+		className = class.Parent
+	}
+}
+
+func hasAPITag(doc string) bool {
+	for _, part := range phpdoc.Parse(doc) {
+		if part.Name == "api" {
+			return true
+		}
+	}
+	return false
+}
@@ -76,3 +76,123 @@ func (v nodeVisitor) LeaveNode(w walker.Walkable)                  {}
 func (v nodeVisitor) EnterNode(w walker.Walkable) bool {
 	return v.enterNode(w)
 }
+
+// interpretString returns the value of PHP string literal s
+// or s itself if it's not a quoted literal.
+func interpretString(s string) string {
+	v, _, ok := interpretStringOffsets(s)
+	if !ok {
+		return s
+	}
+	return v
+}
+
+var stringEscapes = map[byte]byte{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'v':  '\v',
+	'e':  '\x1b',
+	'f':  '\f',
+	'\\': '\\',
+	'$':  '$',
+	'"':  '"',
+}
+
+// interpretStringOffsets returns the value of PHP string literal s
+// along with the positions of its bytes inside s: offsets[i] is the position
+// of the character or escape sequence that produced value[i],
+// and the last offset is the position of the closing quote.
+func interpretStringOffsets(s string) (value string, offsets []int, ok bool) {
+	if len(s) < 2 || !isQuote(rune(s[0])) || s[len(s)-1] != s[0] {
+		return "", nil, false
+	}
+
+	end := len(s) - 1
+	buf := make([]byte, 0, end)
+	offsets = make([]int, 0, end)
+	emit := func(c byte, pos int) {
+		buf = append(buf, c)
+		offsets = append(offsets, pos)
+	}
+
+	for i := 1; i < end; {
+		if s[i] != '\\' || i+1 == end {
+			emit(s[i], i)
+			i++
+			continue
+		}
+
+		next := s[i+1]
+		if s[0] == '\'' {
+			if next == '\\' || next == '\'' {
+				emit(next, i)
+				i += 2
+			} else {
+				emit('\\', i)
+				i++
+			}
+			continue
+		}
+
+		if c, ok := stringEscapes[next]; ok {
+			emit(c, i)
+			i += 2
+			continue
+		}
+
+		switch {
+		case next >= '0' && next <= '7':
+			j, c := i+1, 0
+			for ; j < end && j < i+4 && s[j] >= '0' && s[j] <= '7'; j++ {
+				c = c*8 + int(s[j]-'0')
+			}
+			emit(byte(c), i)
+			i = j
+		case next == 'x' && i+2 < end && isHexDigit(s[i+2]):
+			j, c := i+2, 0
+			for ; j < end && j < i+4 && isHexDigit(s[j]); j++ {
+				c = c*16 + hexDigitValue(s[j])
+			}
+			emit(byte(c), i)
+			i = j
+		case next == 'u' && i+2 < end && s[i+2] == '{':
+			closing := strings.IndexByte(s[i:end], '}')
+			if closing == -1 {
+				emit('\\', i)
+				i++
+				continue
+			}
+			r := 0
+			for _, c := range []byte(s[i+3 : i+closing]) {
+				r = r*16 + hexDigitValue(c)
+			}
+			for _, c := range []byte(string(rune(r))) {
+				emit(c, i)
+			}
+			i += closing + 1
+		default:
+			emit('\\', i)
+			i++
+		}
+	}
+	offsets = append(offsets, end)
+
+	return string(buf), offsets, true
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func hexDigitValue(c byte) int {
+	switch {
+	case isDigit(c):
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return 0
+}
@@ -0,0 +1,140 @@
+package linttest_test
+
+import (
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestUnimplementedMethods(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+interface Reader {
+  /** @return string */
+  public function read();
+}
+
+interface Closer {
+  /** close it */
+  public function close();
+}
+
+interface ReadCloser extends Reader, Closer {}
+
+abstract class Base implements Reader {
+  /** @return int */
+  abstract public function size();
+
+  /** @return string */
+  public function read() { return ""; }
+}
+
+trait Named {
+  /** @return string */
+  abstract public function name();
+}
+
+trait NameImpl {
+  /** @return string */
+  public function name() { return "x"; }
+}
+
+class Complete extends Base implements Closer {
+  use Named, NameImpl;
+
+  /** @return int */
+  public function size() { return 0; }
+
+  /** close it */
+  public function close() {}
+}
+
+class MissingParent extends Base {}
+
+class MissingInterface implements ReadCloser {
+  /** @return string */
+  public function read() { return ""; }
+}
+
+class MissingTrait {
+  use Named;
+}
+
+abstract class AbstractIsFine implements ReadCloser {}
+
+class NotAbstract {
+  /** @return int */
+  abstract public function f();
+}
+`)
+	test.Expect = []string{
+		`Class \MissingParent must implement \Base::size method`,
+		`Class \MissingInterface must implement \Closer::close method`,
+		`Class \MissingTrait must implement \Named::name method`,
+		`Class \NotAbstract contains abstract method f and must be declared abstract`,
+	}
+	test.RunAndMatch()
+}
+
+func TestUnimplementedMethodsCase(t *testing.T) {
+	linttest.SimpleNegativeTest(t, `<?php
+interface I {
+  /** @return int */
+  public function getFoo();
+}
+
+abstract class Base {
+  /** @return int */
+  abstract public function doIt();
+}
+
+trait T {
+  /** @return int */
+  abstract public function fromTrait();
+}
+
+class C extends Base implements I {
+  use T;
+
+  /** @return int */
+  public function getfoo() { return 1; }
+
+  /** @return int */
+  public function DOIT() { return 1; }
+
+  /** @return int */
+  public function FromTrait() { return 1; }
+}
+`)
+}
+
+func TestNewAbstract(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+interface I {}
+
+trait T {}
+
+abstract class Base {
+  /** @return static */
+  public static function create() {
+    return new static();
+  }
+}
+
+class Child extends Base {}
+
+function f() {
+  $_ = new I();
+  $_ = new T();
+  $_ = new Base();
+  $_ = new Child();
+}
+`)
+	test.Expect = []string{
+		`Cannot instantiate interface \I`,
+		`Cannot instantiate trait \T`,
+		`Cannot instantiate abstract class \Base`,
+	}
+	test.RunAndMatch()
+}
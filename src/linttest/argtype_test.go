@@ -0,0 +1,148 @@
+package linttest_test
+
+import (
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestArgType(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+interface Shape {}
+class Circle implements Shape {
+  public function __toString() { return "circle"; }
+}
+class Square {}
+class Base {}
+class Derived extends Base {}
+
+function takesInt(int $x) {}
+function takesShape(Shape $s) {}
+function takesBase(Base $b) {}
+function takesNullableBase(Base $b = null) {}
+function takesArray(array $a) {}
+function takesString(string $s) {}
+function takesInts(int ...$xs) {}
+function takesCallable(callable $c) {}
+function takesAny($x = 0) {}
+
+/** @param string[] $list */
+function takesList($list) {}
+
+function f($unknown) {
+  takesInt("10");
+  takesInt([1]);
+  takesShape(new Circle);
+  takesShape(new Square);
+  takesBase(new Derived);
+  takesBase(new Square);
+  takesBase(null);
+  takesNullableBase(null);
+  takesArray(10);
+  takesArray([]);
+  takesString(new Circle);
+  takesString(new Square);
+  takesInts(1, 2, [3]);
+  takesCallable('strlen');
+  takesCallable(function() {});
+  takesCallable(new Square);
+  takesAny([]);
+  takesList("x");
+  takesInt($unknown);
+
+  $x = $unknown ? new Square : new Derived;
+  takesBase($x);
+}
+`)
+	test.Expect = []string{
+		`Argument 1 passed to takesInt must be of type int, int[] given`,
+		`Argument 1 passed to takesShape must be of type \Shape, \Square given`,
+		`Argument 1 passed to takesBase must be of type \Base, \Square given`,
+		`Argument 1 passed to takesBase must be of type \Base, null given`,
+		`Argument 1 passed to takesArray must be of type array, int given`,
+		`Argument 1 passed to takesString must be of type string, \Square given`,
+		`Argument 3 passed to takesInts must be of type int, int[] given`,
+		`Argument 1 passed to takesCallable must be of type callable, \Square given`,
+		`Argument 1 passed to takesList must be of type string[], string given`,
+	}
+	runFilterMatch(test, "argType")
+}
+
+func TestArgTypeMethods(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+class Point {
+  public function __construct(int $x, int $y) {}
+  public function moveTo(Point $p) {}
+  public static function origin(Point $p = null) {}
+}
+
+function f() {
+  $p = new Point(1, [2]);
+  $p->moveTo($p);
+  $p->moveTo(10);
+  Point::origin(null);
+  Point::origin("0,0");
+}
+`)
+	test.Expect = []string{
+		`Argument 2 passed to \Point constructor must be of type int, int[] given`,
+		`Argument 1 passed to moveTo must be of type \Point, int given`,
+		`Argument 1 passed to origin must be of type \Point|null, string given`,
+	}
+	runFilterMatch(test, "argType")
+}
+
+func TestArgTypePseudoTypes(t *testing.T) {
+	linttest.SimpleNegativeTest(t, `<?php
+interface Traversable {}
+interface Iterator extends Traversable {}
+final class Closure {}
+class Foo {}
+
+function needFoo(Foo $x) {}
+function needClosure(Closure $c) {}
+function needArray(array $a) {}
+function needIterator(Iterator $it) {}
+function needString(string $s) {}
+function needCallable(callable $c) {}
+function needIterable(iterable $it) {}
+
+function fromObject(object $o) {
+  needFoo($o);
+  needCallable($o);
+  needIterable($o);
+}
+
+function fromCallable(callable $c) {
+  needClosure($c);
+  needArray($c);
+  needString($c);
+}
+
+function fromIterable(iterable $it) {
+  needArray($it);
+  needIterator($it);
+}
+`)
+}
+
+func TestArgTypeIterable(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+function needIterable(iterable $it) {}
+function needInt(int $x) {}
+
+function f(iterable $it) {
+  needIterable([1]);
+  needIterable(10);
+  needInt($it);
+}
+`)
+	test.Expect = []string{
+		`Argument 1 passed to needIterable must be of type iterable, int given`,
+		`Argument 1 passed to needInt must be of type int, iterable given`,
+	}
+	runFilterMatch(test, "argType")
+}
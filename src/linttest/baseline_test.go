@@ -0,0 +1,48 @@
+package linttest_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestBaselineFilter(t *testing.T) {
+	oldReports := linttest.GetFileReports(t, `<?php
+function f() {
+  $_ = $x;
+  $_ = array(1);
+}
+`)
+
+	var buf bytes.Buffer
+	if err := linter.NewBaseline(oldReports).Write(&buf); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+	baseline, err := linter.ReadBaseline(&buf)
+	if err != nil {
+		t.Fatalf("read baseline: %v", err)
+	}
+
+	// Lines were shifted and re-indented, array() report was fixed,
+	// one more undefined variable was introduced.
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+/**
+ * Some new comment.
+ */
+function f() {
+    $_ = $x;
+    $_ = $y;
+}
+`)
+	reports, stale := baseline.Filter(test.RunLinter())
+
+	test.Expect = []string{`Undefined variable: y`}
+	test.Match(reports)
+
+	if len(stale) != 1 || stale[0].CheckName != "arraySyntax" {
+		t.Errorf("expected 1 stale arraySyntax entry, got %v", stale)
+	}
+}
@@ -88,6 +88,87 @@ function mt_rand($x = 0, $y = 0) {}`)
 	test.RunAndMatch()
 }
 
+func TestTooManyArgs(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+function one($x) {}
+function variadic($x, ...$rest) {}
+function getArgs() { return func_get_args(); }
+function numArgs() { return func_num_args(); }
+function nested() {
+  return function() { return func_get_args(); };
+}
+function stub($x, $_ = null) {}
+
+interface Iface {
+  public function abstractMethod();
+}
+
+class Base {
+  public function __construct($x) {}
+  public function method($x) {}
+  public static function staticMethod() {}
+}
+
+function f(Base $b, Iface $i, $args) {
+  one(1);
+  one(1, 2);
+  one(...$args);
+  one(1, ...$args);
+  one(1, 2, ...$args);
+  variadic(1, 2, 3);
+  getArgs(1, 2);
+  numArgs(1);
+  nested(1);
+  stub(1, 2, 3);
+  $b->method(1, 2);
+  $i->abstractMethod(1);
+  Base::staticMethod(1);
+  $_ = new Base(1, 2);
+}
+`)
+	test.Expect = []string{
+		`Too many arguments for one`,
+		`Too many arguments for one`,
+		`Too many arguments for nested`,
+		`Too many arguments for method`,
+		`Too many arguments for staticMethod`,
+		`Too many arguments for \Base constructor`,
+	}
+	runFilterMatch(test, "argCount")
+}
+
+func TestTooManyArgsUnresolved(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+class Magic {
+  public function __call($name, $args) {}
+  public static function __callStatic($name, $args) {}
+  private function hidden() {}
+}
+
+trait T {
+  public function f() {
+    $this->fromClass(1, 2);
+    $fn = function() {
+      $this->fromClass(1, 2);
+    };
+    $fn();
+  }
+}
+
+/** @param mixed $m */
+function f(Magic $c, $m) {
+  undefined_func(1, 2);
+  $c->magic(1, 2);
+  $c->hidden(1, 2);
+  Magic::smagic(1, 2);
+  $m->method(1, 2);
+}
+`)
+	runFilterMatch(test, "argCount")
+}
+
 func TestArgsArraysSyntax(t *testing.T) {
 	test := linttest.NewSuite(t)
 	test.AddFile(`<?php
@@ -102,7 +183,7 @@ func TestMethodComplexity(t *testing.T) {
 	funcCode := strings.Repeat("$_ = 0;\n", 9999)
 	test := linttest.NewSuite(t)
 	test.AddFile(`<?php class C { private function f() {` + funcCode + `} }`)
-	test.Expect = []string{"Too big method: more than 150"}
+	test.Expect = []string{"Too big method: more than 150", `Unused private method \C::f`}
 	test.RunAndMatch()
 }
 
@@ -285,7 +366,10 @@ func TestClosureCapture(t *testing.T) {
 			echo $b->other_property;
 		};
 	}`)
-	test.Expect = []string{"other_property does not exist"}
+	test.Expect = []string{
+		"other_property does not exist",
+		"Unused parameter $a",
+	}
 	test.RunAndMatch()
 }
 
@@ -782,6 +866,8 @@ func TestUnused(t *testing.T) {
 		"Unused variable g ",
 		"Unused variable a ",
 		"Unused variable v ",
+		"Unused parameter $arg1",
+		"Unused parameter $arg2",
 	}
 	test.RunAndMatch()
 }
@@ -0,0 +1,137 @@
+package linttest_test
+
+import (
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestDupCond(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+function f($a, $b) {
+  if ($a == 1) {
+    echo 1;
+  } elseif ($b) {
+    echo 2;
+  } elseif ($a == 1) {
+    echo 3;
+  }
+
+  if ($a):
+    echo 1;
+  elseif ($a):
+    echo 2;
+  endif;
+
+  switch ($a) {
+  case 1:
+    echo 1;
+    break;
+  case 'x':
+  case 1:
+    echo 2;
+    break;
+  }
+}
+`)
+	test.Expect = []string{
+		`Duplicated condition in if-elseif chain, the branch is never executed`,
+		`Duplicated condition in if-elseif chain, the branch is never executed`,
+		`Duplicated switch case value, the case is never executed`,
+	}
+	test.RunAndMatch()
+}
+
+func TestDupBranchBody(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+function f($a) {
+  if ($a) {
+    echo 1;
+  } else {
+    echo 1;
+  }
+
+  return $a ? 'x' : 'x';
+}
+`)
+	test.Expect = []string{
+		`Then and else branches are identical`,
+		`Then and else branches of ternary operator are identical`,
+	}
+	test.RunAndMatch()
+}
+
+func TestDupSubExpr(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+class Foo {
+  /** @var int */
+  public $x = 0;
+
+  /** @param mixed $a */
+  public function f($a) {
+    $this->x = $this->x;
+    $a = $a;
+    if ($a == $a) {
+      return $this->x - $this->x;
+    }
+    if ($a && $a) {
+      return 1;
+    }
+    return $a[0] ?? $a[0];
+  }
+}
+`)
+	test.Expect = []string{
+		`Assignment of $this->x to itself`,
+		`Assignment of $a to itself`,
+		`Identical operands on both sides of == operator`,
+		`Identical operands on both sides of - operator`,
+		`Identical operands on both sides of && operator`,
+		`Identical operands on both sides of ?? operator`,
+	}
+	test.RunAndMatch()
+}
+
+func TestDupSideEffects(t *testing.T) {
+	linttest.SimpleNegativeTest(t, `<?php
+/** @return int */
+function next_token() {}
+
+function f($a, $b) {
+  if (next_token() == 1) {
+    echo 1;
+  } elseif (next_token() == 1) {
+    echo 2;
+  }
+
+  switch ($a) {
+  case next_token():
+    break;
+  case next_token():
+    break;
+  }
+
+  $_ = next_token() - next_token();
+  $_ = $a++ == $a++;
+  $_ = $a[$b++] = $a[$b++];
+  $_ = $a + $a;
+  $_ = $a . $a;
+  $_ = $a * $a;
+
+  if ($a) {
+  } else {
+  }
+  if ($a) {
+    echo 1;
+  } elseif ($b) {
+    echo 1;
+  } else {
+    echo 1;
+  }
+  return $a ? $a : $b;
+}
+`)
+}
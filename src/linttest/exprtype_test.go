@@ -913,6 +913,162 @@ namespace PHPSTORM_META {
 	runExprTypeTest(t, &exprTypeTestContext{stubs: stubs, local: local}, tests)
 }
 
+func TestExprTypeNarrowing(t *testing.T) {
+	tests := []exprTypeTest{
+		{`instanceof_guard($x)`, `\Child`},
+		{`instanceof_else($x)`, `\Other`},
+		{`is_string_guard($x)`, `int`},
+		{`null_guard($x)`, `\Child`},
+		{`not_null_branch($x)`, `\Child`},
+		{`isset_branch($x)`, `\Child`},
+		{`and_branch($x)`, `\Child`},
+		{`or_guard($x)`, `\Child`},
+		{`elseif_chain($x)`, `string`},
+		{`is_array_untyped($x)`, `mixed[]`},
+		{`null_assign($x)`, `\Child`},
+		{`no_narrowing_after_if($x)`, `\Child|\Other`},
+		{`var_null_guard($x)`, `\Child`},
+		{`var_not_null_branch($x)`, `\Child`},
+		{`var_assign_after_guard($x)`, `\Child|\Other`},
+	}
+
+	global := `<?php
+class Child {}
+class Other {}
+
+/** @param Child|Other $x */
+function instanceof_guard($x) {
+  if (!$x instanceof Child) {
+    exit;
+  }
+  return $x;
+}
+
+/** @param Child|Other $x */
+function instanceof_else($x) {
+  if ($x instanceof Child) {
+    return new Other();
+  } else {
+    return $x;
+  }
+}
+
+/** @param string|int $x */
+function is_string_guard($x) {
+  if (is_string($x)) {
+    return 0;
+  }
+  return $x;
+}
+
+/** @param Child|null $x */
+function null_guard($x) {
+  if ($x === null) {
+    throw new Exception("null");
+  }
+  return $x;
+}
+
+/** @param Child|null $x */
+function not_null_branch($x) {
+  if ($x !== null) {
+    return $x;
+  }
+  return new Child();
+}
+
+/** @param Child|null $x */
+function isset_branch($x) {
+  if (isset($x)) {
+    return $x;
+  }
+  return new Child();
+}
+
+/** @param Child|Other|null $x */
+function and_branch($x) {
+  if ($x !== null && $x instanceof Child) {
+    return $x;
+  }
+  return new Child();
+}
+
+/** @param Child|Other|null $x */
+function or_guard($x) {
+  if ($x === null || $x instanceof Other) {
+    return new Child();
+  }
+  return $x;
+}
+
+/** @param string|int|float $x */
+function elseif_chain($x) {
+  if (is_int($x)) {
+    return "";
+  } elseif (is_float($x)) {
+    return "";
+  } else {
+    return $x;
+  }
+}
+
+function is_array_untyped($x) {
+  if (is_array($x)) {
+    return $x;
+  }
+  return [];
+}
+
+/** @param Child|null $x */
+function null_assign($x) {
+  if ($x === null) {
+    $x = new Child();
+  }
+  return $x;
+}
+
+/** @param Child|Other $x */
+function no_narrowing_after_if($x) {
+  if ($x instanceof Child) {
+    echo "child";
+  }
+  return $x;
+}
+
+/** @param Child|null $x */
+function var_null_guard($x) {
+  /** @var Child|null $y */
+  $y = $x;
+  if ($y === null) {
+    return new Child();
+  }
+  return $y;
+}
+
+/** @param Child|null $x */
+function var_not_null_branch($x) {
+  /** @var Child|null $y */
+  $y = $x;
+  if ($y !== null) {
+    return $y;
+  }
+  return new Child();
+}
+
+/** @param Child|null $x */
+function var_assign_after_guard($x) {
+  /** @var Child|null $y */
+  $y = $x;
+  if ($y === null) {
+    return new Child();
+  }
+  $y = new Other();
+  return $y;
+}`
+	local := `$x = null;`
+	runExprTypeTest(t, &exprTypeTestContext{global: global, local: local}, tests)
+}
+
 func runExprTypeTest(t *testing.T, ctx *exprTypeTestContext, tests []exprTypeTest) {
 	if ctx == nil {
 		ctx = &exprTypeTestContext{}
@@ -0,0 +1,78 @@
+package linttest_test
+
+import (
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestReportFingerprint(t *testing.T) {
+	oldReports := linttest.GetFileReports(t, `<?php
+class Foo {
+  public function f() {
+    $_ = $x;
+    $_ = $x;
+  }
+}
+`)
+	newReports := linttest.GetFileReports(t, `<?php
+
+class Foo {
+  /** Some comment. */
+  public function f() {
+        $_ = $x;
+        $_ = $x;
+  }
+
+  public function g() {
+    $_ = $x;
+  }
+}
+`)
+
+	var oldUndefined, newUndefined []string
+	for _, r := range oldReports {
+		if r.CheckName() == "undefined" {
+			oldUndefined = append(oldUndefined, r.Fingerprint())
+		}
+	}
+	for _, r := range newReports {
+		if r.CheckName() == "undefined" {
+			newUndefined = append(newUndefined, r.Fingerprint())
+		}
+	}
+
+	if len(oldUndefined) != 1 || len(newUndefined) != 2 {
+		t.Fatalf("unexpected reports count: old=%d new=%d", len(oldUndefined), len(newUndefined))
+	}
+	if oldUndefined[0] != newUndefined[0] {
+		t.Errorf("fingerprint changed after unrelated edits: %s vs %s", oldUndefined[0], newUndefined[0])
+	}
+	if newUndefined[0] == newUndefined[1] {
+		t.Errorf("reports in different methods have the same fingerprint %s", newUndefined[0])
+	}
+}
+
+func TestReportFingerprintFiles(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+echo $x;
+`)
+	test.AddFile(`<?php
+echo $x;
+`)
+
+	var fingerprints []string
+	for _, r := range test.RunLinter() {
+		if r.CheckName() == "undefined" {
+			fingerprints = append(fingerprints, r.Fingerprint())
+		}
+	}
+
+	if len(fingerprints) != 2 {
+		t.Fatalf("unexpected reports count: %d", len(fingerprints))
+	}
+	if fingerprints[0] == fingerprints[1] {
+		t.Errorf("reports in different files have the same fingerprint %s", fingerprints[0])
+	}
+}
@@ -0,0 +1,70 @@
+package linttest_test
+
+import (
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestFixes(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+	}{
+		{
+			name:   "arraySyntax",
+			before: `<?php function f($x = array(1)) { return ARRAY (array(), [array( 'a' => 2 )]); }`,
+			after:  `<?php function f($x = [1]) { return [[], [[ 'a' => 2 ]]]; }`,
+		},
+		{
+			name:   "redundantCast",
+			before: `<?php function f(int $x, string $s) { return [(int)$x, (string) $s, (int)($x), (int)$s]; }`,
+			after:  `<?php function f(int $x, string $s) { return [$x, $s, (int)($x), (int)$s]; }`,
+		},
+		{
+			name: "caseBreak",
+			before: `<?php
+function f($x) {
+  switch ($x) {
+  case 1:
+    echo 1;
+  case 2:
+    echo 2;
+  }
+}`,
+			after: `<?php
+function f($x) {
+  switch ($x) {
+  case 1:
+    echo 1;
+  // fallthrough
+  case 2:
+    echo 2;
+  }
+}`,
+		},
+		{
+			name:   "oldStyleConstructor",
+			before: `<?php class Foo { public function foo() {} } class Bar { public function Bar() {} public function __construct() {} }`,
+			after:  `<?php class Foo { public function __construct() {} } class Bar { public function Bar() {} public function __construct() {} }`,
+		},
+	}
+
+	for _, tt := range tests {
+		test := linttest.NewSuite(t)
+		test.AddFile(tt.before)
+		var reports []*linter.Report
+		for _, r := range test.RunLinter() {
+			if r.CheckName() == tt.name {
+				reports = append(reports, r)
+			}
+		}
+		edits, _ := linter.SelectFixes(reports)
+		have := string(linter.ApplyTextEdits([]byte(tt.before), edits))
+		if have != tt.after {
+			t.Errorf("%s:\nhave: %s\nwant: %s", tt.name, have, tt.after)
+		}
+	}
+}
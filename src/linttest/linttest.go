@@ -147,6 +147,11 @@ func (s *Suite) RunLinter() []*linter.Report {
 	}
 
 	meta.SetIndexingComplete(true)
+	linter.UpdateTaintSummaries(func(ch chan linter.FileInfo) {
+		for _, f := range s.Files {
+			ch <- linter.FileInfo{Filename: f.Name, Contents: f.Data}
+		}
+	})
 
 	var reports []*linter.Report
 	for _, f := range s.Files {
@@ -0,0 +1,249 @@
+package linttest_test
+
+import (
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestNullable(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+class Foo {
+  /** @var int */
+  public $prop = 0;
+
+  /** @var Foo|null */
+  public $next;
+
+  /** @return int */
+  public function f() { return 0; }
+}
+
+/** @return Foo|null */
+function find() {}
+
+/** @return int[]|null */
+function ints() {}
+
+function unchecked() {
+  $foo = find();
+  $_ = $foo->f();
+  $_ = $foo->prop;
+
+  $ints = ints();
+  $_ = $ints[0];
+}
+
+/** @param Foo|null $foo */
+function property($foo) {
+  if ($foo) {
+    $_ = $foo->next->f();
+  }
+}
+`)
+	test.Expect = []string{
+		`Call to method f() on possibly null value of type \Foo|null`,
+		`Fetch of property prop on possibly null value of type \Foo|null`,
+		`Array access on possibly null value of type int[]|null`,
+		`Call to method f() on possibly null value of type \Foo|null`,
+	}
+	test.RunAndMatch()
+}
+
+func TestNullableChecked(t *testing.T) {
+	linttest.SimpleNegativeTest(t, `<?php
+class Foo {
+  /** @var int */
+  public $prop = 0;
+
+  /** @var Foo|null */
+  public $next;
+
+  /** @return int */
+  public function f() { return 0; }
+}
+
+/** @return Foo|null */
+function find() {}
+
+/** @return int[]|null */
+function ints() {}
+
+function guard() {
+  $foo = find();
+  if (!$foo) {
+    return 0;
+  }
+  return $foo->f();
+}
+
+function branch() {
+  $foo = find();
+  if ($foo instanceof Foo) {
+    return $foo->prop;
+  }
+  return 0;
+}
+
+function expressions() {
+  $foo = find();
+  $_ = $foo && $foo->f();
+  $_ = !$foo || $foo->f();
+  $_ = $foo ? $foo->f() : 0;
+  $_ = isset($foo->prop);
+  $_ = $foo->prop ?? 0;
+
+  $ints = ints();
+  $_ = $ints[0] ?? 0;
+  $_ = isset($ints[0]);
+}
+
+function assigned() {
+  $foo = find();
+  if (!$foo) {
+    $foo = new Foo();
+  }
+  return $foo->f();
+}
+
+function property() {
+  $foo = new Foo();
+  if ($foo->next) {
+    return $foo->next->f();
+  }
+  return 0;
+}
+`)
+}
+
+func TestNullableVarNarrowing(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+class Foo {
+  /** @return int */
+  public function m() { return 0; }
+}
+
+/** @return Foo|null */
+function g() {}
+
+function guard() {
+  /** @var Foo|null $y */
+  $y = g();
+  if ($y === null) {
+    return 0;
+  }
+  return $y->m();
+}
+`)
+	runFilterMatch(test, "nullable")
+}
+
+func TestNullablePropertyGuards(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+class Foo {
+  /** @return int */
+  public function m() { return 0; }
+}
+
+class Holder {
+  /** @var Foo|null */
+  public $foo;
+
+  /** @var Foo|null */
+  public static $shared;
+
+  public function earlyReturn() {
+    if ($this->foo === null) {
+      return 0;
+    }
+    return $this->foo->m();
+  }
+
+  public function lazyInit() {
+    if ($this->foo === null) {
+      $this->foo = new Foo();
+    }
+    return $this->foo->m();
+  }
+
+  public function staticLazyInit() {
+    if (!self::$shared) {
+      self::$shared = new Foo();
+    }
+    return self::$shared->m();
+  }
+
+  public function assignedNull() {
+    $this->foo = null;
+    if (rand()) {
+      $this->foo = new Foo();
+    }
+    return $this->foo->m();
+  }
+
+  public function notGuarded() {
+    if ($this->foo === null) {
+      echo "null";
+    }
+    return $this->foo->m();
+  }
+}
+`)
+	test.Expect = []string{
+		`Call to method m() on possibly null value of type \Foo|null`,
+		`Call to method m() on possibly null value of type \Foo|null`,
+	}
+	runFilterMatch(test, "nullable")
+}
+
+func TestNullableTernaryAndEmpty(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+class Foo {
+  /** @return int */
+  public function m() { return 0; }
+}
+
+/** @return Foo|null */
+function g() {}
+
+function ternary() {
+  $x = g();
+  $y = $x !== null ? $x : new Foo();
+  $z = $x ?: new Foo();
+  $w = !$x ? new Foo() : $x;
+  return $y->m() + $z->m() + $w->m();
+}
+
+function emptyGuard() {
+  $w = g();
+  if (empty($w)) {
+    return 0;
+  }
+  return $w->m();
+}
+
+function notEmptyBranch() {
+  $w = g();
+  if (!empty($w)) {
+    return $w->m();
+  }
+  return 0;
+}
+
+function emptyBranch() {
+  $w = g();
+  if (empty($w)) {
+    return $w->m();
+  }
+  return 0;
+}
+`)
+	test.Expect = []string{
+		`Call to method m() on possibly null value of type \Foo|null`,
+	}
+	runFilterMatch(test, "nullable")
+}
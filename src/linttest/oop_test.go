@@ -186,7 +186,8 @@ $m->foo->bar->method();
 }
 
 func TestIteratorForeach(t *testing.T) {
-	linttest.SimpleNegativeTest(t, `<?php
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
 interface Iterator extends Traversable {
   public function current();
   public function key();
@@ -218,6 +219,10 @@ function testForeach($xml_str) {
   }
 }
 `)
+	test.Expect = []string{
+		`Unused parameter $name`,
+	}
+	test.RunAndMatch()
 }
 
 func TestSimpleXMLElementForeach(t *testing.T) {
@@ -243,7 +248,8 @@ function testForeach($xml_str) {
 }
 
 func TestSimpleXMLElement(t *testing.T) {
-	linttest.SimpleNegativeTest(t, `<?php
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
 class SimpleXMLElement {
   /** @return SimpleXMLElement */
   private function __get($name) {}
@@ -281,6 +287,13 @@ function simpleIteratorReassign($xml_string) {
   $_ = $iter->current();
 }
 `)
+	test.Expect = []string{
+		`Fetch of property foo on possibly null value of type \SimpleXMLIterator|null`,
+		`Unused parameter $xml_str`,
+		`Unused parameter $xml_str`,
+		`Unused parameter $xml_string`,
+	}
+	test.RunAndMatch()
 }
 
 func TestLateStaticBindingForeach(t *testing.T) {
@@ -579,13 +592,41 @@ function fn4($f4) {
 }`)
 	test.Expect = []string{
 		`Call to undefined method {\File}->name()`,
-		`Call to undefined method {\File|\Video}->filename()`,
+		`Call to undefined method {\Video}->filename()`,
 		`Call to undefined method {\File}->name()`,
-		`Call to undefined method {\File|\Video}->filename()`,
+		`Call to undefined method {\Video}->filename()`,
 	}
 	test.RunAndMatch()
 }
 
+func TestInstanceOfGuard(t *testing.T) {
+	linttest.SimpleNegativeTest(t, `<?php
+class File {
+  /** @return string */
+  public function filename() { return ""; }
+}
+class Video {
+  /** @return string */
+  public function name() { return ""; }
+}
+
+/** @param File|Video $f */
+function guard($f) {
+  if (!$f instanceof File) {
+    return $f->name();
+  }
+  return $f->filename();
+}
+
+/** @param File|null $f */
+function nullGuard($f) {
+  if (!$f) {
+    return "";
+  }
+  return $f->filename();
+}`)
+}
+
 func TestInstanceOfElseif1(t *testing.T) {
 	linttest.SimpleNegativeTest(t, `<?php
 class File {
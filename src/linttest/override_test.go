@@ -0,0 +1,166 @@
+package linttest_test
+
+import (
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestOverride(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+class Base {
+  public function __construct($a) {
+    $this->privateMethod($a);
+  }
+
+  /** @param int $a */
+  public function fewer($a, $b) {}
+
+  /** @param int $a */
+  public function required($a, $b = 0) {}
+
+  /** @param int $a */
+  public function byRef(&$a) {}
+
+  /** @param int $a */
+  public function variadic(...$a) {}
+
+  /** ok */
+  public function extended($a) {}
+
+  /** ok */
+  public function spread($a, $b) {}
+
+  /** ok */
+  protected function widened() {}
+
+  /** narrowed */
+  public function narrowed() {}
+
+  /** ok */
+  public static function staticMethod() {}
+
+  /** ok */
+  public function instanceMethod() {}
+
+  private function privateMethod($a) {}
+}
+
+class Child extends Base {
+  public function __construct() {
+    $this->privateMethod();
+  }
+
+  /** @param int $a */
+  public function fewer($a) {}
+
+  /** @param int $a */
+  public function required($a, $b) {}
+
+  /** @param int $a */
+  public function byRef($a) {}
+
+  /** @param int $a */
+  public function variadic($a) {}
+
+  /** ok */
+  public function extended($a, $b = 0) {}
+
+  /** ok */
+  public function spread(...$args) {}
+
+  /** ok */
+  public function widened() {}
+
+  /** narrowed */
+  protected function narrowed() {}
+
+  /** ok */
+  public function staticMethod() {}
+
+  /** ok */
+  public static function instanceMethod() {}
+
+  private function privateMethod() {}
+}
+`)
+	test.Expect = []string{
+		`Declaration of \Child::fewer must be compatible with \Base::fewer: fewer parameters`,
+		`Declaration of \Child::required must be compatible with \Base::required: more required parameters`,
+		`Declaration of \Child::byRef must be compatible with \Base::byRef: parameter $a must be passed by reference`,
+		`Declaration of \Child::variadic must be compatible with \Base::variadic: variadic parameter is missing`,
+		`Access level to \Child::narrowed must be public (as in \Base::narrowed) or weaker`,
+		`Cannot make static method \Base::staticMethod non static in class \Child`,
+		`Cannot make non static method \Base::instanceMethod static in class \Child`,
+	}
+	test.RunAndMatch()
+}
+
+func TestOverrideInterface(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+interface Creatable {
+  /** @param int $a */
+  public function __construct($a);
+}
+
+interface Handler extends Creatable {
+  /** @param int $a */
+  public function handle($a);
+}
+
+abstract class AbstractHandler implements Handler {}
+
+class Impl extends AbstractHandler {
+  public function __construct() {}
+
+  /** handle */
+  public static function handle() {}
+}
+`)
+	test.Expect = []string{
+		`Declaration of \Impl::__construct must be compatible with \Creatable::__construct: fewer parameters`,
+		`Declaration of \Impl::handle must be compatible with \Handler::handle: fewer parameters`,
+		`Cannot make non static method \Handler::handle static in class \Impl`,
+	}
+	test.RunAndMatch()
+}
+
+func TestOverrideInterfaceWithParent(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+interface I {
+  /**
+   * @param int $a
+   * @param int $b
+   */
+  public function m($a, $b);
+}
+
+interface J {
+  /** @param int $a */
+  public function m($a);
+}
+
+class P implements J {
+  /** @param int $a */
+  public function m($a) {}
+}
+
+class C extends P implements I {
+  /** @param int $a */
+  public function m($a) {}
+}
+
+class D extends P {
+  /** m */
+  public function m() {}
+}
+`)
+	test.Expect = []string{
+		`Declaration of \C::m must be compatible with \I::m: fewer parameters`,
+		`Declaration of \D::m must be compatible with \P::m: fewer parameters`,
+	}
+	runFilterMatch(test, "override")
+}
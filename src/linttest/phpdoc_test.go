@@ -111,6 +111,7 @@ func TestPHPDocPresence(t *testing.T) {
 	test.Expect = []string{
 		`Missing PHPDoc for "pub" public method`,
 		`Missing PHPDoc for "traitPub" public method`,
+		`Unused private method \TheClass::priv`,
 	}
 	test.RunAndMatch()
 }
@@ -137,6 +138,7 @@ func TestPHPDocSyntax(t *testing.T) {
 		`expected a type, found '-'; if you want to express 'any' type, use 'mixed' on line 4`,
 		`malformed @param $a tag (maybe type is missing?) on line 5`,
 		`malformed @param tag (maybe var is missing?) on line 6`,
+		`Unused parameter $a`,
 	}
 	test.RunAndMatch()
 }
@@ -0,0 +1,144 @@
+package linttest_test
+
+import (
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+const printfStubs = `<?php
+/** @return string */
+function sprintf($format, ...$args) {}
+
+/** @return int */
+function printf($format, ...$args) {}
+
+/** @return string */
+function vsprintf($format, $args) {}
+
+/** @return mixed */
+function sscanf($str, $format, &$v1 = 0, &$v2 = 0, &$v3 = 0) {}
+`
+
+func TestPrintf(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddNolintFile(printfStubs)
+	test.AddFile(`<?php
+function f($a, $b) {
+  $_ = sprintf('%s and %d', $a);
+  $_ = sprintf('%s', $a, $b);
+  $_ = sprintf('%y', $a);
+  $_ = sprintf('100%', $a);
+  $_ = sprintf('%1$s %s', $a, $b);
+  $_ = sprintf('%2$s', $a);
+  $_ = sprintf('%0$s', $a);
+  printf("%s\n");
+  $_ = vsprintf('%s %s', [$a]);
+  $_ = sscanf($a, '%d-%d', $x);
+  $_ = sscanf($a, '%d-%[a-z', $x);
+}
+`)
+	test.Expect = []string{
+		`Too few arguments for sprintf: format string requires 2, 1 given`,
+		`Too many arguments for sprintf: format string requires 1, 2 given`,
+		`Invalid format string for sprintf: unknown format specifier 'y'`,
+		`Invalid format string for sprintf: missing format specifier at end of string`,
+		`Format string for sprintf mixes numbered %n$ and sequential placeholders`,
+		`Too few arguments for sprintf: format string requires 2, 1 given`,
+		`Invalid format string for sprintf: argument number must be greater than zero`,
+		`Too few arguments for printf: format string requires 1, 0 given`,
+		`Too few arguments for vsprintf: format string requires 2, 1 given`,
+		`Too few arguments for sscanf: format string requires 2, 1 given`,
+		`Invalid format string for sscanf: unmatched [ in format string`,
+	}
+	test.RunAndMatch()
+}
+
+func TestPrintfLike(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddNolintFile(printfStubs)
+	test.AddFile(`<?php
+/**
+ * @printf-like $format
+ * @param int    $level
+ * @param string $format
+ */
+function logf($level, $format, ...$args) {
+  echo $level, sprintf($format, ...$args);
+}
+
+class Logger {
+  /** @printf-like */
+  public function errorf($format, ...$args) {
+    echo vsprintf($format, $args);
+  }
+
+  /** @printf-like $msg */
+  public static function fatalf($msg, ...$args) {
+    echo vsprintf($msg, $args);
+  }
+}
+
+/** @printf-like $fmt */
+function unknown($format) {
+  echo $format;
+}
+
+function f($a) {
+  logf(1, 'value: %d', $a);
+  logf(1, 'values: %d, %d', $a);
+
+  $l = new Logger();
+  $l->errorf('%s', $a);
+  $l->errorf('%s');
+  Logger::fatalf('%q', $a);
+}
+`)
+	test.Expect = []string{
+		`@printf-like refers to unknown param $fmt on line 1`,
+		`Too few arguments for logf: format string requires 2, 1 given`,
+		`Too few arguments for errorf: format string requires 1, 0 given`,
+		`Invalid format string for fatalf: unknown format specifier 'q'`,
+	}
+	test.RunAndMatch()
+}
+
+func TestPrintfGood(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddNolintFile(printfStubs)
+	test.AddFile(`<?php
+function f($a, $b, $format, $args) {
+  $_ = sprintf('%s is 100%% %d', $a, $b);
+  $_ = sprintf('%1$s %2$s %1$s', $a, $b);
+  $_ = sprintf("%'*10s|%-10s|%+05.2f|%u|%x|%e", $a, $b, 1.5, 1, 255, 1.0);
+  $_ = sprintf($format, $a);
+  $_ = sprintf('%s %s', ...$args);
+  $_ = vsprintf('%s %s', $args);
+  $_ = vsprintf('%s %s', [$a, $b]);
+  $_ = sscanf($a, '%d-%d');
+  $_ = sscanf($a, '%d-%*d-%[^-]-%s', $x, $y, $z);
+  printf("%s\n", $a);
+}
+`)
+	test.RunAndMatch()
+}
+
+func TestPrintfEscapes(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddNolintFile(printfStubs)
+	test.AddFile(`<?php
+function f($a, $b) {
+  $_ = sprintf("\x25s and \045d", $a, $b);
+  $_ = sprintf("\u{25}s", $a);
+  $_ = sprintf("\x25s\n\t\$%s");
+  $_ = sprintf('\x25s', $a);
+  $_ = sprintf("100\x25", $a);
+}
+`)
+	test.Expect = []string{
+		`Too few arguments for sprintf: format string requires 2, 0 given`,
+		`Too many arguments for sprintf: format string requires 0, 1 given`,
+		`Invalid format string for sprintf: missing format specifier at end of string`,
+	}
+	test.RunAndMatch()
+}
@@ -0,0 +1,162 @@
+package linttest_test
+
+import (
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestUnusedPrivate(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+class Foo {
+  const PUB = 1;
+  private const USED = 2;
+  private const DEFAULT_USED = 3;
+  private const UNUSED = 4;
+
+  private $used = self::DEFAULT_USED;
+  private $unused;
+  private static $staticUsed;
+  private static $staticUnused;
+
+  public function __construct() {
+    $this->used = self::USED;
+    $this->used .= "x";
+    self::$staticUsed = $this->fromClosure();
+    $this->usedMethod();
+    Foo::staticMethod();
+  }
+
+  private function __clone() {}
+
+  private function usedMethod() {
+    return array_map([$this, 'callback'], [1]);
+  }
+
+  private function callback($x) { return $x; }
+
+  private static function staticMethod() {}
+
+  private function unusedMethod() {}
+
+  private function closureMethod() {}
+
+  private function fromClosure() {
+    return function() {
+      return $this->closureMethod();
+    };
+  }
+}
+`)
+	test.Expect = []string{
+		`Unused private constant \Foo::UNUSED`,
+		`Unused private property \Foo::$unused`,
+		`Unused private property \Foo::$staticUnused`,
+		`Unused private method \Foo::unusedMethod`,
+	}
+	runFilterMatch(test, "unusedPrivate")
+}
+
+func TestUnusedPrivateDynamic(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+class DynamicProps {
+  private $x;
+  private function m() {}
+
+  public function get($name) {
+    return $this->$name;
+  }
+}
+
+class MagicCall {
+  private function m() {}
+  private $x;
+
+  public function __call($name, $args) {
+    return $this->$name(...$args);
+  }
+}
+
+class MagicGet {
+  private $x;
+
+  public function __get($name) {
+    return null;
+  }
+}
+
+trait T {
+  public function useX() {
+    return $this->x;
+  }
+}
+
+class WithTrait {
+  use T;
+  private $x;
+}
+`)
+	test.Expect = []string{
+		`Unused private method \DynamicProps::m`,
+		`Unused private property \MagicCall::$x`,
+	}
+	runFilterMatch(test, "unusedPrivate")
+}
+
+func TestUnusedPrivateStringCallables(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+class Callables {
+  public function run() {
+    array_map([self::class, 'viaClassArray'], [1]);
+    array_map(['self', 'viaSelfArray'], [1]);
+    array_map('self::viaString', [1]);
+    array_map("static::viaDoubleQuoted", [1]);
+    array_map('Callables::viaClassString', [1]);
+    return 'self::';
+  }
+
+  private static function viaClassArray($x) { return $x; }
+  private static function viaSelfArray($x) { return $x; }
+  private static function viaString($x) { return $x; }
+  private static function viaDoubleQuoted($x) { return $x; }
+  private static function viaClassString($x) { return $x; }
+  private static function unused($x) { return $x; }
+}
+`)
+	test.Expect = []string{
+		`Unused private method \Callables::unused`,
+	}
+	runFilterMatch(test, "unusedPrivate")
+}
+
+func TestUnusedPrivateInitializers(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+class Defaults {
+  private const PARAM = 1;
+  private const CLOSURE_PARAM = 2;
+  private const PROP = 3;
+  private const STATIC_PROP = 4;
+  private const CONST_VALUE = 5;
+  private const UNUSED = 6;
+
+  const PUB = self::CONST_VALUE;
+
+  public $prop = self::PROP;
+  public static $staticProp = [self::STATIC_PROP];
+
+  public function f($x = self::PARAM) {
+    return function($y = self::CLOSURE_PARAM) use($x) {
+      return $x + $y;
+    };
+  }
+}
+`)
+	test.Expect = []string{
+		`Unused private constant \Defaults::UNUSED`,
+	}
+	runFilterMatch(test, "unusedPrivate")
+}
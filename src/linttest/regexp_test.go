@@ -0,0 +1,130 @@
+package linttest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+const pregStubs = `<?php
+/** @return int */
+function preg_match($pattern, $subject, &$matches = [], $flags = 0, $offset = 0) {}
+
+/** @return string */
+function preg_replace($pattern, $replacement, $subject, $limit = -1) {}
+
+/** @return string[] */
+function preg_split($pattern, $subject, $limit = -1, $flags = 0) {}
+
+/** @return string */
+function preg_replace_callback_array($patterns, $subject) {}
+`
+
+func TestRegexp(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddNolintFile(pregStubs)
+	test.AddFile(`<?php
+function f($s) {
+  $_ = preg_match('/(foo/', $s);
+  $_ = preg_match('/foo)/', $s);
+  $_ = preg_match('/[a-z/', $s);
+  $_ = preg_match('/[z-a]/', $s);
+  $_ = preg_match('/[\w-.]/', $s);
+  $_ = preg_match('/*foo/', $s);
+  $_ = preg_match('/a{3,1}/', $s);
+  $_ = preg_match('/foo/gi', $s);
+  $_ = preg_match('/foo', $s);
+  $_ = preg_match('foo', $s);
+  $_ = preg_match('/(a)\2/', $s);
+  $_ = preg_match('/(?<x>a)\k<y>/', $s);
+  $_ = preg_match('/(?<x>a)(?<x>b)/', $s);
+  $_ = preg_match('/\i/', $s);
+  $_ = preg_match('/(?Q)/', $s);
+  $_ = preg_match('/[[:alfa:]]/', $s);
+  $_ = preg_split("/\\/", $s);
+  $_ = preg_replace('/(\d+)-(\d+)/', '$2-$1-$3', $s);
+  $_ = preg_replace('/(\d+)/', '${2}\1', $s);
+  $_ = preg_replace(['/a/', '/(b/'], '', $s);
+  $_ = preg_replace_callback_array(['/a/e' => 'trim'], $s);
+}
+`)
+	test.Expect = []string{
+		`Invalid regexp: missing closing parenthesis`,
+		`Invalid regexp: unmatched closing parenthesis`,
+		`Invalid regexp: missing terminating ] for character class`,
+		`Invalid regexp: range out of order in character class`,
+		`Invalid regexp: invalid range in character class`,
+		`Invalid regexp: quantifier does not follow a repeatable item`,
+		`Invalid regexp: numbers out of order in {} quantifier`,
+		`Invalid regexp: unknown modifier 'g'`,
+		`Invalid regexp: no ending delimiter '/' found`,
+		`Invalid regexp: delimiter must not be alphanumeric or backslash`,
+		`Invalid regexp: reference to non-existent subpattern 2`,
+		`Invalid regexp: reference to non-existent subpattern "y"`,
+		`Invalid regexp: two named subpatterns have the same name "x"`,
+		`Invalid regexp: unrecognized character follows \`,
+		`Invalid regexp: unrecognized character after (? or (?-`,
+		`Invalid regexp: unknown POSIX class name`,
+		`Invalid regexp: no ending delimiter '/' found`,
+		`Replacement refers to non-existent group 3`,
+		`Replacement refers to non-existent group 2`,
+		`Invalid regexp: missing closing parenthesis`,
+		`Invalid regexp: unknown modifier 'e'`,
+	}
+	test.RunAndMatch()
+}
+
+func TestRegexpGood(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddNolintFile(pregStubs)
+	test.AddFile(`<?php
+function f($s, $pattern) {
+  $_ = preg_match('/^\d+(?:\.\d+)?$/', $s);
+  $_ = preg_match('~^https?://[^/]+/~i', $s);
+  $_ = preg_match('{^\{(\w+)\}$}', $s);
+  $_ = preg_match('(\(a\))', $s);
+  $_ = preg_match('/[]a-z\-_.\\\\]+/u', $s);
+  $_ = preg_match('/[\w.-]+@[\w.-]+/', $s);
+  $_ = preg_match('/(?P<year>\d{4})-(?<month>\d\d)-(?\'day\'\d\d) (?P=year) \k{month}/', $s);
+  $_ = preg_match('/(a)(?:b|c)*?\1 \g1 \g{-1} (?1) (?R)? a++ b{2,}+ c{,3}/', $s);
+  $_ = preg_match('/(?i)abc(?-i:def)(?=x)(?!y)(?<=z)(?<!w)(?>v)(?|(a)|(b))/', $s);
+  $_ = preg_match('/(?(?=a)ab|cd) (?(1)a|b) (?#comment)*/', $s);
+  $_ = preg_match('/\p{Lu}\P{L}\x{1F600}\Q(*+?\E[[:alpha:][:^digit:]]/u', $s);
+  $_ = preg_match('/ a # comment with ( and [
+     b /x', $s);
+  $_ = preg_match("/^\s*\$/m", $s);
+  $_ = preg_match("/\x41\t\\d/", $s);
+  $_ = preg_match($pattern, $s);
+  $_ = preg_match("/$pattern/", $s);
+  $_ = preg_replace('/(a)(b)/', '$2${1}\\0\\\\3 $', $s);
+  $_ = preg_replace('/(?|(a)|(b))/', '$2', $s);
+  $_ = preg_replace(['/a/', '/b/'], ['$1', '$2'], $s);
+  $_ = preg_replace_callback_array(['/a/' => 'trim', '/(b)/i' => 'trim'], $s);
+}
+`)
+	test.RunAndMatch()
+}
+
+func TestRegexpReportPosition(t *testing.T) {
+	reports := linttest.GetFileReports(t, `<?php
+function f($s) {
+  return preg_match('/[a-z]+(\d{3,1})/', $s);
+}
+`)
+	for _, r := range reports {
+		if r.CheckName() != "regexp" {
+			continue
+		}
+		want := []string{
+			`  return preg_match('/[a-z]+(\d{3,1})/', $s);`,
+			`                               ^^^^^`,
+		}
+		have := strings.Split(r.String(), "\n")[1:]
+		if strings.Join(have, "\n") != strings.Join(want, "\n") {
+			t.Errorf("unexpected excerpt:\n%s", strings.Join(have, "\n"))
+		}
+		return
+	}
+	t.Fatalf("regexp is not reported")
+}
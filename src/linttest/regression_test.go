@@ -23,7 +23,8 @@ func TestIssue1(t *testing.T) {
 	}`)
 }
 func TestIssue2(t *testing.T) {
-	linttest.SimpleNegativeTest(t, `<?php
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
 	function rand() { return 4; }
 
 	interface DateTimeInterface {
@@ -36,7 +37,7 @@ func TestIssue2(t *testing.T) {
 	}
 
 	function test(): \DateTimeInterface {
-		return 0; // this should return error as well :)
+		return 0;
 	}
 
 	function a(TestClassInterface $testClass): string
@@ -47,6 +48,10 @@ func TestIssue2(t *testing.T) {
 			return test()->format('U');
 		}
 	}`)
+	test.Expect = []string{
+		`Return value of \test must be of type \DateTimeInterface, int returned`,
+	}
+	test.RunAndMatch()
 }
 
 func TestIssue3(t *testing.T) {
@@ -436,6 +441,7 @@ function trailing_exit_while($xs) {
 		"Unreachable code",
 		"Unreachable code",
 		"Unreachable code",
+		"Unused parameter $xs",
 	}
 
 	test.RunAndMatch()
@@ -611,13 +617,15 @@ function trailing_exit_while($xs) {
 		"Unreachable code",
 		"Unreachable code",
 		"Unreachable code",
+		"Unused parameter $xs",
 	}
 
 	test.RunAndMatch()
 }
 
 func TestIssue78_3(t *testing.T) {
-	linttest.SimpleNegativeTest(t, `<?php
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
 $xs = [1, 2];
 trailing_exit_if($xs);
 trailing_exit_foreach($xs);
@@ -746,6 +754,10 @@ function trailing_exit_while($xs) {
   exit;
 }
 `)
+	test.Expect = []string{
+		"Unused parameter $xs",
+	}
+	test.RunAndMatch()
 }
 
 func TestIssue128(t *testing.T) {
@@ -787,6 +799,7 @@ $_ = $bad1;
 		`Undefined variable: bad3`,
 		`Property {mixed}->x does not exist`,
 		`Variable might have not been defined: y1`,
+		`Unused parameter $arr`,
 	}
 	test.RunAndMatch()
 }
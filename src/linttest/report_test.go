@@ -0,0 +1,48 @@
+package linttest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestMultilineReportString(t *testing.T) {
+	reports := linttest.GetFileReports(t, `<?php
+function f($x) {
+  switch ($x) {
+  case 1:
+    echo 1;
+    echo 2;
+    echo 3;
+    echo 4;
+    echo 5;
+  case 2:
+    echo 2;
+  }
+}
+`)
+	for _, r := range reports {
+		if r.CheckName() != "caseBreak" {
+			continue
+		}
+		if r.Line() != 4 || r.EndLine() != 9 {
+			t.Errorf("unexpected range: lines %d-%d", r.Line(), r.EndLine())
+		}
+		want := []string{
+			`  case 1:`,
+			`  ^^^^^^^`,
+			`    echo 1;`,
+			`    echo 2;`,
+			`... (2 more lines)`,
+			`    echo 5;`,
+			`    ^^^^^^^`,
+		}
+		have := strings.Split(r.String(), "\n")[1:]
+		if strings.Join(have, "\n") != strings.Join(want, "\n") {
+			t.Errorf("unexpected excerpt:\n%s", strings.Join(have, "\n"))
+		}
+		return
+	}
+	t.Fatalf("caseBreak is not reported")
+}
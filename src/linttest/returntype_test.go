@@ -0,0 +1,98 @@
+package linttest_test
+
+import (
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestReturnType(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+interface Shape {}
+class Circle implements Shape {}
+class Square {}
+
+function good1(): Shape { return new Circle; }
+function bad1(): Shape { return new Square; }
+
+/** @return int[] */
+function good2() { return [1, 2]; }
+
+/** @return int[] */
+function bad2() { return 10; }
+
+/** @return Shape|null */
+function good3($x) {
+  if ($x) {
+    return new Circle;
+  }
+  return null;
+}
+
+function bad3(): int {
+  return;
+}
+
+function void1(): void {
+  return;
+}
+
+function void2(): void {
+  return 10;
+}
+
+/** @return string */
+function missing1($x) {
+  if ($x) {
+    return "x";
+  }
+}
+
+/** @return string */
+function notMissing1($x) {
+  if ($x) {
+    return "x";
+  } else {
+    throw new Exception("no x");
+  }
+}
+
+/** @return string */
+function notMissing2() {
+  while (true) {
+    return "x";
+  }
+}
+
+/** @return int */
+function generator() {
+  yield 1;
+}
+
+/** @return int */
+function stub() {}
+
+class Point {
+  public function __construct() {
+    return $this;
+  }
+
+  /** @return static */
+  public function self() { return $this; }
+
+  /** @return Point */
+  public function notPoint() { return new Square; }
+}
+`)
+	test.Expect = []string{
+		`Return value of \bad1 must be of type \Shape, \Square returned`,
+		`Return value of \bad2 must be of type int[], int returned`,
+		`Return value of \bad3 must be of type int, none returned`,
+		`Void function \void2 must not return a value`,
+		`Missing return statement: \missing1 must return string`,
+		`Constructor \Point::__construct must not return a value`,
+		`Return value of \Point::notPoint must be of type \Point, \Square returned`,
+	}
+	runFilterMatch(test, "returnType")
+}
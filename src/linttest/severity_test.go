@@ -0,0 +1,34 @@
+package linttest_test
+
+import (
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestSeverityOverrides(t *testing.T) {
+	linter.SeverityOverrides = map[string]int{
+		"arraySyntax": linter.LevelError,
+		"undefined":   linter.LevelDoNotReject,
+	}
+	defer func() { linter.SeverityOverrides = nil }()
+
+	reports := linttest.GetFileReports(t, `<?php
+function f() {
+  $_ = array($x);
+}
+`)
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(reports))
+	}
+	for _, r := range reports {
+		want := linter.SeverityOverrides[r.CheckName()]
+		if r.Level() != want {
+			t.Errorf("%s: level is %d, want %d", r.CheckName(), r.Level(), want)
+		}
+		if r.IsCritical() != (want != linter.LevelDoNotReject) {
+			t.Errorf("%s: unexpected IsCritical=%v", r.CheckName(), r.IsCritical())
+		}
+	}
+}
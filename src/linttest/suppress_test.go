@@ -0,0 +1,117 @@
+package linttest_test
+
+import (
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestIgnoreNextLine(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+function f() {
+  // noverify-ignore-next-line undefined
+  $_ = $x;
+  # noverify-ignore-next-line
+  $_ = $y;
+  // noverify-ignore-next-line arraySyntax
+  $_ = $z;
+  $_ = $w;
+}
+`)
+	test.Expect = []string{
+		`Undefined variable: z`,
+		`Undefined variable: w`,
+		`Suppression comment doesn't suppress anything, it can be removed`,
+	}
+	test.RunAndMatch()
+}
+
+func TestSuppressTag(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+/** @noverify-suppress undefined */
+function f() {
+  $_ = $x;
+  $_ = array(1);
+}
+
+class Foo {
+  /**
+   * @noverify-suppress undefined, arraySyntax
+   */
+  public function g() {
+    $_ = $x;
+    $_ = array(1);
+  }
+
+  /**
+   * @noverify-suppress argCount
+   */
+  public function h() {}
+
+  /** @noverify-suppress noSuchCheck */
+  public function i() {}
+}
+
+function g() {
+  /** @noverify-suppress undefined */
+  $_ = $x;
+  $_ = $y;
+}
+`)
+	test.Expect = []string{
+		`Use of old array syntax (use short form instead)`,
+		`Suppression comment doesn't suppress anything, it can be removed`,
+		`Unknown check noSuchCheck in suppression comment`,
+		`Undefined variable: y`,
+	}
+	test.RunAndMatch()
+}
+
+func TestIgnoreNextLineMarkerBoundary(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+function f() {
+  // noverify-ignore-next-linefoo
+  $_ = $x;
+  // noverify-ignore-next-line-undefined
+  $_ = $y;
+  //noverify-ignore-next-line	undefined
+  $_ = $z;
+}
+`)
+	test.Expect = []string{
+		`Undefined variable: x`,
+		`Undefined variable: y`,
+	}
+	test.RunAndMatch()
+}
+
+func TestSuppressDisabledChecks(t *testing.T) {
+	linttest.SimpleNegativeTest(t, `<?php
+/** @noverify-suppress unusedSymbol */
+function unusedFunc() {}
+
+function f() {
+  // noverify-ignore-next-line taint
+  echo 1;
+  // noverify-ignore-next-line taint, unusedSymbol
+  echo 2;
+}
+`)
+}
+
+func TestSuppressEnabledChecks(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+function f() {
+  // noverify-ignore-next-line taint
+  echo 1;
+}
+`)
+	test.Expect = []string{
+		`Suppression comment doesn't suppress anything, it can be removed`,
+	}
+	runTaint(test)
+}
@@ -0,0 +1,350 @@
+package linttest_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+	"github.com/Levsha-cc/noverify/src/linttest"
+	"github.com/Levsha-cc/noverify/src/meta"
+)
+
+// leaveFileCounter counts the files walked by custom checkers after indexing.
+type leaveFileCounter struct {
+	linter.RootCheckerDefaults
+}
+
+var (
+	countLeaveFile int32
+	leftFiles      int32
+)
+
+func (leaveFileCounter) AfterLeaveFile() {
+	if atomic.LoadInt32(&countLeaveFile) != 0 && meta.IsIndexingComplete() {
+		atomic.AddInt32(&leftFiles, 1)
+	}
+}
+
+func init() {
+	linter.RegisterRootChecker(func(ctx *linter.RootContext) linter.RootChecker {
+		return leaveFileCounter{}
+	})
+}
+
+func runTaint(test *linttest.Suite) {
+	linter.TaintAnalysis = true
+	defer func() { linter.TaintAnalysis = false }()
+	test.RunAndMatch()
+}
+
+func TestTaint(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddNolintFile(`<?php
+/** @return mixed */
+function mysqli_query($link, $query) {}
+
+/** @return string */
+function exec($command) {}
+
+/** @return string */
+function htmlspecialchars($s) {}
+
+/** @return string */
+function escapeshellarg($s) {}
+
+/** @return string */
+function trim($s) {}
+`)
+	test.AddFile(`<?php
+function sql($db) {
+  $id = $_GET['id'];
+  $query = "SELECT * FROM users WHERE id = $id";
+  mysqli_query($db, $query);
+}
+
+function command() {
+  $arg = trim($_POST['arg']);
+  exec('ls ' . $arg);
+  exec('ls ' . escapeshellarg($arg));
+}
+
+function xss() {
+  $name = $_REQUEST['name'] ?? '';
+  echo "Hello, " . $name;
+  echo htmlspecialchars($name);
+  echo (int)$name;
+}
+
+function includes($page) {
+  include $_COOKIE['page'] . '.php';
+  include $page . '.php';
+}
+
+function branches($cond) {
+  $x = 'safe';
+  if ($cond) {
+    $x = $_GET['x'];
+  }
+  echo $x;
+}
+
+function reassigned() {
+  $x = $_GET['x'];
+  $x = 'safe';
+  echo $x;
+}
+
+function loop() {
+  foreach ($_GET as $key => $value) {
+    echo $key, $value;
+  }
+}
+`)
+	test.Expect = []string{
+		`User input is passed to mysqli_query without sanitizing`,
+		`User input is passed to exec without sanitizing`,
+		`User input is passed to echo without sanitizing`,
+		`User input is passed to include without sanitizing`,
+		`User input is passed to echo without sanitizing`,
+		`User input is passed to echo without sanitizing`,
+		`User input is passed to echo without sanitizing`,
+	}
+	runTaint(test)
+}
+
+func TestTaintSummaries(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddNolintFile(`<?php
+/** @return string */
+function htmlspecialchars($s) {}
+`)
+	test.AddFile(`<?php
+function param($name) {
+  return $_GET[$name];
+}
+
+function wrap($prefix, $s) {
+  return $prefix . $s;
+}
+
+function escape($s) {
+  return htmlspecialchars($s);
+}
+
+class Request {
+  /** @return string */
+  public function get($name) {
+    return $_POST[$name];
+  }
+}
+
+function f() {
+  echo param('a');
+  echo wrap('a', $_GET['b']);
+  echo wrap($_GET['b'], 'a');
+  echo wrap('a', 'b');
+  echo escape($_GET['a']);
+
+  $r = new Request();
+  echo $r->get('a');
+}
+`)
+	test.Expect = []string{
+		`User input is passed to echo without sanitizing`,
+		`User input is passed to echo without sanitizing`,
+		`User input is passed to echo without sanitizing`,
+		`User input is passed to echo without sanitizing`,
+	}
+	runTaint(test)
+}
+
+const taintWrappers = `<?php
+/** @return string */
+function htmlspecialchars($s) {}
+
+function input($k) {
+  return $_GET[$k];
+}
+
+function wrap() {
+  return input('x');
+}
+
+function wrap2() {
+  $v = wrap();
+  return $v;
+}
+
+function escape($s) {
+  return htmlspecialchars($s);
+}
+
+function esc2($s) {
+  return escape($s);
+}
+
+function esc3($s) {
+  return esc2($s);
+}
+
+function recursive($s, $n) {
+  if ($n > 0) {
+    return recursive($s, $n - 1);
+  }
+  return escape($s);
+}
+
+class Request {
+  /** @return string */
+  public function raw() {
+    return wrap();
+  }
+
+  /** @return string */
+  public function safe() {
+    return esc2($this->raw());
+  }
+}
+`
+
+func TestTaintNestedSources(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddNolintFile(taintWrappers)
+	test.AddFile(`<?php
+function f() {
+  echo wrap();
+  echo wrap2();
+  $r = new Request();
+  echo $r->raw();
+}
+`)
+	test.Expect = []string{
+		`User input is passed to echo without sanitizing`,
+		`User input is passed to echo without sanitizing`,
+		`User input is passed to echo without sanitizing`,
+	}
+	runTaint(test)
+}
+
+func TestTaintNestedSanitizers(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddNolintFile(taintWrappers)
+	test.AddFile(`<?php
+function f() {
+  echo esc2($_GET['a']);
+  echo esc3($_GET['a']);
+  $r = new Request();
+  echo $r->safe();
+  echo recursive($_GET['a'], 3);
+}
+`)
+	runTaint(test)
+}
+
+func TestTaintClosureUse(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+function f() {
+  $x = $_GET['a'];
+  $y = 'safe';
+  $fn = function() use ($x, $y) {
+    echo $x;
+    echo $y;
+  };
+  $fn();
+}
+`)
+	test.Expect = []string{
+		`User input is passed to echo without sanitizing`,
+	}
+	runTaint(test)
+}
+
+func TestTaintDisabled(t *testing.T) {
+	linttest.SimpleNegativeTest(t, `<?php
+function f() {
+  echo $_GET['a'];
+}
+`)
+}
+
+func TestTaintFreeBuiltins(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddNolintFile(`<?php
+/** @return int */
+function count($a) {}
+
+/** @return int */
+function strlen($s) {}
+
+/** @return string */
+function md5($s) {}
+
+/** @return int */
+function crc32($s) {}
+
+/** @return bool */
+function in_array($needle, $haystack) {}
+
+/** @return bool */
+function is_numeric($x) {}
+
+/** @return string */
+function sprintf($format, ...$args) {}
+`)
+	test.AddFile(`<?php
+function f() {
+  echo count($_GET);
+  echo strlen($_POST['a']);
+  echo md5($_GET['a']);
+  echo crc32($_GET['a']);
+  echo in_array($_GET['a'], ['x', 'y']);
+  echo is_numeric($_GET['a']);
+  echo sprintf("%d items, %.2f total", $_POST['n'], $_POST['total']);
+  echo sprintf('%2$05d: %1$x', $_POST['a'], $_POST['b']);
+  echo $_SERVER['REQUEST_TIME'];
+  echo $_SERVER['SERVER_PORT'];
+
+  echo sprintf("%d: %s", $_POST['n'], $_POST['name']);
+  echo sprintf('%2$s', 'safe', $_POST['name']);
+  echo sprintf($_GET['format'], 1);
+  echo $_SERVER['HTTP_USER_AGENT'];
+}
+`)
+	test.Expect = []string{
+		`User input is passed to echo without sanitizing`,
+		`User input is passed to echo without sanitizing`,
+		`User input is passed to echo without sanitizing`,
+		`User input is passed to echo without sanitizing`,
+	}
+	runTaint(test)
+}
+
+func TestTaintPassesSkipCustomCheckers(t *testing.T) {
+	atomic.StoreInt32(&countLeaveFile, 1)
+	atomic.StoreInt32(&leftFiles, 0)
+	defer atomic.StoreInt32(&countLeaveFile, 0)
+
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+function f() {
+  return g($_GET['a']);
+}
+
+function g($x) {
+  return $x;
+}
+
+function h() {
+  echo f();
+}
+`)
+	test.Expect = []string{
+		`User input is passed to echo without sanitizing`,
+	}
+	runTaint(test)
+
+	if n := atomic.LoadInt32(&leftFiles); n != 1 {
+		t.Errorf("custom checkers walked the file %d times, want 1", n)
+	}
+}
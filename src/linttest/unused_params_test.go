@@ -0,0 +1,193 @@
+package linttest_test
+
+import (
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func TestUnusedParams(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+function f($used, $unused, &$ref, $_) {
+  echo $used;
+}
+
+class Foo {
+  /**
+   * @param int $x
+   * @param int $y
+   */
+  public function method($x, $y) {
+    return $x;
+  }
+
+  /** @param int $x */
+  public static function staticMethod($x) {
+    return 1;
+  }
+}
+
+function g() {
+  return function($a, $b, $c) {
+    return $b;
+  };
+}
+`)
+	test.Expect = []string{
+		`Unused parameter $unused`,
+		`Unused parameter $ref`,
+		`Unused parameter $y`,
+		`Unused parameter $x`,
+		`Unused parameter $c`,
+	}
+	test.RunAndMatch()
+}
+
+func TestUnusedParamsInherited(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+interface Handler {
+  /**
+   * @param string $event
+   * @param mixed $data
+   */
+  public function handle($event, $data);
+}
+
+abstract class Base {
+  /** @param int $x */
+  abstract public function run($x);
+
+  /** @param int $x */
+  public function __construct($x) {
+    echo $x;
+  }
+}
+
+class Impl extends Base implements Handler {
+  /**
+   * @param string $event
+   * @param mixed $data
+   */
+  public function handle($event, $data) {
+    echo $event;
+  }
+
+  /**
+   * @param int $x
+   * @param int $extra
+   */
+  public function run($x, $extra = 0) {
+    return 1;
+  }
+
+  /** @param int $y */
+  public function __construct($y) {
+    parent::__construct(1);
+  }
+}
+`)
+	test.Expect = []string{
+		`Unused parameter $extra`,
+		`Unused parameter $y`,
+	}
+	test.RunAndMatch()
+}
+
+func TestUnusedParamsOverridden(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+class P {
+  /** @param mixed $req */
+  public function hook($req) {
+    return 1;
+  }
+
+  /** @param mixed $req */
+  protected function deepHook($req) {
+    return 1;
+  }
+
+  /** @param mixed $x */
+  private function helper($x) {
+    return 1;
+  }
+
+  /** @param mixed $x */
+  public function notOverridden($x) {
+    return $this->helper(1);
+  }
+}
+
+class C extends P {
+  /** @param mixed $req */
+  public function HOOK($req) {
+    return $req;
+  }
+
+  /** @param mixed $x */
+  private function helper($x) {
+    return $x;
+  }
+}
+
+class D extends C {
+  /** @param mixed $req */
+  protected function deepHook($req) {
+    return $req;
+  }
+}
+`)
+	test.Expect = []string{
+		`Unused parameter $x`,
+		`Unused parameter $x`,
+	}
+	runFilterMatch(test, "unusedParam")
+}
+
+func TestUnusedParamsNegative(t *testing.T) {
+	linttest.SimpleNegativeTest(t, `<?php
+function func_get_args() {}
+function compact($name) {}
+
+function stub($x) {}
+
+function variadic($x) {
+  return func_get_args();
+}
+
+function compacted($x, $y) {
+  return compact('x', 'y');
+}
+
+function captured($x) {
+  return function() use($x) {
+    return $x;
+  };
+}
+
+function closureArgs() {
+  return function($a, $b) {
+    return $b;
+  };
+}
+
+trait T {
+  /** @param int $x */
+  public function fromTrait($x) {
+    return 1;
+  }
+}
+
+class Magic {
+  /**
+   * @param string $name
+   * @param mixed[] $args
+   */
+  public function __call($name, $args) {
+    return 1;
+  }
+}
+`)
+}
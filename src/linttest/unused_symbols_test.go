@@ -0,0 +1,159 @@
+package linttest_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/Levsha-cc/noverify/src/linter"
+	"github.com/Levsha-cc/noverify/src/linttest"
+)
+
+func runUnusedSymbols(test *linttest.Suite) {
+	linter.UnusedSymbols = true
+	defer func() { linter.UnusedSymbols = false }()
+
+	reports := test.RunLinter()
+	reports = append(reports, linter.UnusedSymbolReports()...)
+	test.Match(reports)
+}
+
+func TestUnusedSymbols(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+namespace App;
+
+interface Handler {
+  /** handle the request */
+  public function handle();
+}
+
+class Base {
+  const USED = 1;
+  const UNUSED = 2;
+
+  /** used from other file */
+  public function used() {}
+
+  /** not called anywhere */
+  public function unused() {}
+
+  /** overridden below */
+  public function overridden() {}
+
+  public function __toString() { return ""; }
+}
+
+class Child extends Base implements Handler {
+  /** implements interface */
+  public function handle() {}
+
+  /** overrides parent */
+  public function overridden() {}
+
+  /** called through callable string */
+  public static function callback() {}
+}
+
+class UnusedClass {}
+
+function used_func() {}
+
+function unused_func() {}
+
+function callback_func() {}
+
+function recursive($x) { return recursive($x); }
+`)
+	test.AddFile(`<?php
+use App\Child;
+
+function main() {
+  $c = new Child();
+  $c->used();
+  $c->handle();
+  echo Child::USED;
+  \App\used_func();
+  array_map('App\callback_func', [1]);
+  call_user_func('App\Child::callback');
+}
+
+main();
+`)
+	test.AddNolintFile(`<?php
+function array_map($callback, $arr) {}
+function call_user_func($callback, ...$args) {}
+`)
+	test.Expect = []string{
+		`Unused class constant \App\Base::UNUSED`,
+		`Unused public method \App\Base::unused`,
+		`Unused public method \App\Base::overridden`,
+		`Unused class \App\UnusedClass`,
+		`Unused function \App\unused_func`,
+	}
+	runUnusedSymbols(test)
+}
+
+func TestUnusedSymbolsTypes(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+interface ParentIface {}
+interface ChildIface extends ParentIface {}
+trait T {}
+class Hinted {}
+class Returned {}
+class Caught extends Exception {}
+class Checked {}
+class Defaults { const VALUE = 1; }
+
+final class Impl implements ChildIface {
+  use T;
+}
+
+/** @return Returned */
+function f(?Hinted $h, $x = Defaults::VALUE): Returned {
+  try {
+    $impl = new Impl();
+    echo $impl instanceof ParentIface;
+  } catch (Caught $_) {
+    echo $x instanceof Checked;
+  }
+  return new Returned();
+}
+
+f(new Hinted());
+`)
+	test.AddNolintFile(`<?php
+class Exception {}
+`)
+	test.Expect = []string{
+		`Unused parameter $h`,
+	}
+	runUnusedSymbols(test)
+}
+
+func TestUnusedSymbolsAllowList(t *testing.T) {
+	test := linttest.NewSuite(t)
+	test.AddFile(`<?php
+/** @api */
+class Controller {
+  const ROUTE = "/";
+
+  /** action */
+  public function index() {}
+}
+
+/** @api */
+function hook() {}
+
+function on_init() {}
+
+function unused() {}
+`)
+	test.Expect = []string{
+		`Unused function \unused`,
+	}
+
+	linter.UnusedSymbolsAllowRegex = regexp.MustCompile(`^\\on_`)
+	defer func() { linter.UnusedSymbolsAllowRegex = nil }()
+	runUnusedSymbols(test)
+}
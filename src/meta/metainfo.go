@@ -94,6 +94,13 @@ func (i *info) GetClassOrTrait(nm string) (res ClassInfo, ok bool) {
 	return res, ok
 }
 
+// IterateClasses calls cb for every known class.
+func (i *info) IterateClasses(cb func(className string, class ClassInfo)) {
+	for nm, class := range i.allClasses {
+		cb(nm, class)
+	}
+}
+
 func (i *info) NumClasses() int {
 	return len(i.allClasses)
 }
@@ -260,6 +267,43 @@ func (i *info) AddConstantsNonLocked(filename string, m ConstantsMap) {
 	}
 }
 
+// SetFunctionTaintNonLocked replaces taint summary of the function declared in the file.
+// It reports whether the summary has changed.
+func (i *info) SetFunctionTaintNonLocked(filename, nm string, t Taint) bool {
+	fns := i.perFileFunctions[filename]
+	fn, ok := fns[nm]
+	if !ok || fn.Taint == t {
+		return false
+	}
+	prevFn := fn
+	fn.Taint = t
+	fns[nm] = fn
+	if all, ok := i.allFunctions[nm]; ok && all.Pos == prevFn.Pos {
+		i.allFunctions[nm] = fn
+	}
+	return true
+}
+
+// SetMethodTaintNonLocked replaces taint summary of the class or trait method declared in the file.
+// It reports whether the summary has changed.
+func (i *info) SetMethodTaintNonLocked(filename, className, methodName string, t Taint) bool {
+	changed := false
+	for _, classes := range []ClassesMap{i.perFileClasses[filename], i.perFileTraits[filename]} {
+		class, ok := classes[className]
+		if !ok {
+			continue
+		}
+		m, ok := class.Methods[methodName]
+		if !ok || m.Taint == t {
+			continue
+		}
+		m.Taint = t
+		class.Methods[methodName] = m
+		changed = true
+	}
+	return changed
+}
+
 func (i *info) AddToGlobalScopeNonLocked(filename string, sc *Scope) {
 	sc.Iterate(func(nm string, typ *TypesMap, alwaysDefined bool) {
 		i.AddVarName(nm, typ, "global", alwaysDefined)
@@ -267,14 +311,21 @@ func (i *info) AddToGlobalScopeNonLocked(filename string, sc *Scope) {
 }
 
 type FuncParam struct {
-	IsRef bool
-	Name  string
-	Typ   *TypesMap
+	IsRef      bool
+	IsVariadic bool
+	Name       string
+	Typ        *TypesMap
+
+	// TypDeclared is false if Typ was inferred from the default value
+	// instead of being specified by a type hint or phpdoc.
+	TypDeclared bool
 }
 
 type PhpDocInfo struct {
 	Deprecated      bool
 	DeprecationNote string
+	PrintfLike      bool // function formats its args like sprintf, see @printf-like
+	PrintfFormat    int  // index of the format param of printf-like function
 }
 
 type FuncInfo struct {
@@ -284,7 +335,10 @@ type FuncInfo struct {
 	Typ          *TypesMap
 	AccessLevel  AccessLevel
 	Static       bool
-	ExitFlags    int // if function has exit/die/throw, then ExitFlags will be <> 0
+	Abstract     bool  // abstract methods and methods of interfaces
+	Variadic     bool  // if function has ...$args param or uses func_get_args(), then it accepts any number of args
+	ExitFlags    int   // if function has exit/die/throw, then ExitFlags will be <> 0
+	Taint        Taint // taint of the return value, only computed if taint analysis is enabled
 	Doc          PhpDocInfo
 }
 
@@ -346,6 +400,8 @@ type ClassInfo struct {
 	Methods          FunctionsMap
 	Properties       PropertiesMap // both instance and static properties are inside. Static properties have "$" prefix
 	Constants        ConstantsMap
+	Abstract         bool
+	IsInterface      bool
 }
 
 type ClassParseState struct {
@@ -379,6 +435,12 @@ func GetInternalFunctionInfo(fn string) (info FuncInfo, ok bool) {
 	return info, ok
 }
 
+// IsInternalClass reports whether the class is defined in the stubs.
+func IsInternalClass(className string) bool {
+	_, ok := internalClasses[className]
+	return ok
+}
+
 func GetInternalFunctionOverrideInfo(fn string) (info FuncInfoOverride, ok bool) {
 	info, ok = internalFunctionOverrides[fn]
 	return info, ok
@@ -23,6 +23,8 @@ type Scope struct {
 	vars             map[string]*scopeVar // variables declared in the scope
 	inInstanceMethod bool
 	inClosure        bool
+
+	tainted map[string]Taint // variables that can contain tainted values, used by taint analysis
 }
 
 // NewScope creates new empty scope
@@ -158,6 +160,7 @@ func (s *Scope) DelVarName(name, reason string) {
 		fmt.Println("unset $" + name + " - " + reason)
 	}
 	delete(s.vars, name)
+	delete(s.tainted, name)
 }
 
 // ReplaceVarName replaces variable with specified types to the scope
@@ -174,6 +177,20 @@ func (s *Scope) ReplaceVarName(name string, typ *TypesMap, reason string, always
 	}
 }
 
+// NarrowVarName replaces variable types with the ones that are known
+// from the control flow, like the types left after a null check.
+//
+// Unlike ReplaceVarName, it also replaces types of the variables
+// declared with phpdoc @var, but keeps them declared.
+func (s *Scope) NarrowVarName(name string, typ *TypesMap, reason string, alwaysDefined bool) {
+	oldVar, ok := s.vars[name]
+	s.vars[name] = &scopeVar{
+		typesMap:      typ,
+		alwaysDefined: alwaysDefined,
+		noReplace:     ok && oldVar.noReplace,
+	}
+}
+
 // AddVarName adds variable with specified types to the scope
 func (s *Scope) addVarName(name string, typ *TypesMap, reason string, alwaysDefined, noReplace bool) {
 	v, ok := s.vars[name]
@@ -285,9 +302,45 @@ func (s *Scope) Clone() *Scope {
 	}
 	res.inInstanceMethod = s.inInstanceMethod
 	res.inClosure = s.inClosure
+	if len(s.tainted) != 0 {
+		res.tainted = make(map[string]Taint, len(s.tainted))
+		for k, v := range s.tainted {
+			res.tainted[k] = v
+		}
+	}
 	return res
 }
 
+// GetVarTaint returns taint of the variable values.
+func (s *Scope) GetVarTaint(name string) Taint {
+	return s.tainted[name]
+}
+
+// SetVarTaint replaces taint of the variable values.
+func (s *Scope) SetVarTaint(name string, t Taint) {
+	if t == 0 {
+		delete(s.tainted, name)
+		return
+	}
+	if s.tainted == nil {
+		s.tainted = make(map[string]Taint)
+	}
+	s.tainted[name] = t
+}
+
+// AddVarTaint adds t to the taint of the variable values.
+func (s *Scope) AddVarTaint(name string, t Taint) {
+	s.SetVarTaint(name, s.GetVarTaint(name)|t)
+}
+
+// MergeTaint adds taint of the variables from other scope,
+// e.g. from a scope of the branch that could be executed.
+func (s *Scope) MergeTaint(other *Scope) {
+	for name, t := range other.tainted {
+		s.AddVarTaint(name, t)
+	}
+}
+
 func scopeVarName(v *expr.Variable) (string, bool) {
 	switch vn := v.VarName.(type) {
 	case *node.Identifier:
@@ -0,0 +1,36 @@
+package meta
+
+// Taint is a set of origins of a value that are tracked by taint analysis:
+// user input and params of the function that computes the value.
+//
+// The zero value means that the value is not tainted.
+type Taint uint64
+
+// TaintInput is a taint of values that come from user input, like $_GET.
+const TaintInput Taint = 1
+
+// TaintAnyParam is a taint of values that can come from any function param.
+const TaintAnyParam = ^TaintInput
+
+// maxTaintParams is a number of params that can be tracked.
+const maxTaintParams = 63
+
+// TaintParam returns a taint of the i-th function param.
+// Params beyond the maximum number of tracked params are not tainted.
+func TaintParam(i int) Taint {
+	if i >= maxTaintParams {
+		return 0
+	}
+	return 1 << uint(i+1)
+}
+
+// IsInput reports whether the value can come from user input.
+func (t Taint) IsInput() bool {
+	return t&TaintInput != 0
+}
+
+// HasParam reports whether the value can come from the i-th param.
+func (t Taint) HasParam(i int) bool {
+	p := TaintParam(i)
+	return p != 0 && t&p != 0
+}
@@ -169,7 +169,8 @@ func isConstantFloatArray(items []node.Node) bool {
 	return true
 }
 
-// CustomType specifies a mapping between some AST structure and concrete type (e.g. for <expr> instanceof <something>)
+// CustomType specifies a mapping between some AST structure and concrete type (e.g. for <expr> instanceof <something>).
+// If several custom types match the same structure, the last one is used.
 type CustomType struct {
 	Node node.Node
 	Typ  *meta.TypesMap
@@ -185,9 +186,10 @@ func exprTypeLocalCustom(sc *meta.Scope, cs *meta.ClassParseState, n node.Node,
 		return &meta.TypesMap{}
 	}
 
-	for _, c := range custom {
-		if nodeAwareDeepEqual(c.Node, n) {
-			return c.Typ
+	// The last matching type is the most recent one.
+	for i := len(custom) - 1; i >= 0; i-- {
+		if nodeAwareDeepEqual(custom[i].Node, n) {
+			return custom[i].Typ
 		}
 	}
 
@@ -8,6 +8,12 @@ import (
 	"github.com/z7zmey/php-parser/position"
 )
 
+// NodesEqual reports whether a and b are the same syntax trees,
+// their positions and comments are ignored.
+func NodesEqual(a, b node.Node) bool {
+	return nodeAwareDeepEqual(a, b)
+}
+
 // Like reflect.DeepEqual but knows how to compare node.Node by ignoring
 // freefloating text and positions
 func nodeAwareDeepEqual(a, b interface{}) bool {
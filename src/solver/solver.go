@@ -343,6 +343,32 @@ func Implements(className string, interfaceName string) bool {
 	}
 }
 
+// InstanceOf checks if className is typeName or one of its descendants:
+// className extends typeName class, implements or extends typeName interface.
+func InstanceOf(className string, typeName string) bool {
+	if className == typeName {
+		return true
+	}
+
+	if interfaceExtends(className, typeName, make(map[string]struct{}, 8)) {
+		return true
+	}
+
+	visited := make(map[string]struct{}, 8)
+	for class, ok := meta.Info.GetClass(className); ok; class, ok = meta.Info.GetClass(class.Parent) {
+		if _, ok := visited[class.Parent]; ok || class.Parent == "" {
+			break
+		}
+		visited[class.Parent] = struct{}{}
+
+		if class.Parent == typeName {
+			return true
+		}
+	}
+
+	return Implements(className, typeName)
+}
+
 // interfaceExtends checks if interface orig extends interface parent
 func interfaceExtends(orig string, parent string, visited map[string]struct{}) bool {
 	if _, ok := visited[orig]; ok {